@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+
+	"time-machine/internal/config"
+	"time-machine/internal/models"
+	"time-machine/internal/timekeeper"
+)
+
+// runCLI executes a non-interactive command and returns the exit code
+func runCLI(gitService *timekeeper.Service, args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "status":
+		return runStatus(gitService, args[1:], stdout, stderr)
+	case "save":
+		return runSave(gitService, args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, models.T("Неизвестная команда: %s\n"), args[0])
+		return 2
+	}
+}
+
+// parseGlobalFlags reads the flags given before the subcommand and returns
+// the directory to work in, empty for the current one, and the rest of the
+// arguments
+func parseGlobalFlags(args []string, stderr io.Writer) (string, []string, error) {
+	flags := flag.NewFlagSet("git-checkpoint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var dir string
+	flags.StringVar(&dir, "C", "", models.T("работать в этой папке, а не в текущей"))
+	flags.StringVar(&dir, "dir", "", models.T("работать в этой папке, а не в текущей"))
+	if err := flags.Parse(args); err != nil {
+		return "", nil, err
+	}
+	return dir, flags.Args(), nil
+}
+
+// Environment variables overriding the checkpoint author
+const (
+	envAuthorName  = "VIBEGIT_AUTHOR_NAME"
+	envAuthorEmail = "VIBEGIT_AUTHOR_EMAIL"
+)
+
+// runSave creates a checkpoint of every change without the TUI
+func runSave(gitService *timekeeper.Service, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("save", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	message := flags.String("m", "", models.T("описание сейва (по умолчанию checkpoint.defaultMessage)"))
+	authorName := flags.String("author-name", os.Getenv(envAuthorName), models.T("имя автора этого сейва (или $")+envAuthorName+")")
+	stagedOnly := flags.Bool("staged", false, models.T("сохранить только подготовленное (git add), без остальных изменений"))
+	untracked := flags.Bool("untracked", gitService.IncludeUntracked(), models.T("включать новые файлы (--untracked=false — только изменения уже известных git файлов)"))
+	authorEmail := flags.String("author-email", os.Getenv(envAuthorEmail), models.T("email автора этого сейва (или $")+envAuthorEmail+")")
+	yes := flags.Bool("yes", false, models.T("не спрашивать подтверждения, даже если файлов больше checkpoint.confirmFiles"))
+	date := flags.String("date", "", models.T("дата сейва вместо текущей: 2006-01-02, \"2006-01-02 15:04\" или RFC 3339"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var when time.Time
+	if *date != "" {
+		parsed, err := parseDate(*date)
+		if err != nil {
+			fmt.Fprintf(stderr, models.T("Ошибка: %v\n"), err)
+			return 2
+		}
+		when = parsed
+	}
+
+	if *message == "" {
+		*message = gitService.DefaultMessage()
+	}
+
+	opts := timekeeper.CheckpointOptions{
+		AuthorName:    *authorName,
+		AuthorEmail:   *authorEmail,
+		StagedOnly:    *stagedOnly,
+		SkipUntracked: !*untracked,
+		Date:          when,
+		Confirmed:     *yes,
+	}
+	switch msg := gitService.CreateCheckpoint(*message, opts).(type) {
+	case models.CheckpointCreatedMsg:
+		if !msg.Success {
+			fmt.Fprintln(stderr, msg.Message)
+			return 1
+		}
+		fmt.Fprintln(stdout, msg.Message)
+		printHooks(gitService.RunHooks(config.HookAfterCheckpoint), stdout, stderr)
+		return 0
+	case models.ConfirmCheckpointMsg:
+		fmt.Fprintf(stderr, models.T(models.TextConfirmCheckpointCLI)+"\n", msg.Files, msg.Limit)
+		return 1
+	case models.ErrMsg:
+		fmt.Fprintf(stderr, models.T("Ошибка: %v\n"), timekeeper.Explain(msg.Error))
+		return 1
+	default:
+		fmt.Fprintf(stderr, models.T("Ошибка: неожиданный ответ %T\n"), msg)
+		return 1
+	}
+}
+
+// printHooks writes the report of the hooks run, if any
+func printHooks(msg tea.Msg, stdout, stderr io.Writer) {
+	hooks, ok := msg.(models.HooksMsg)
+	if !ok {
+		return
+	}
+	failures, outputs := hookReport(hooks)
+	for _, line := range outputs {
+		fmt.Fprintln(stdout, line)
+	}
+	for _, line := range failures {
+		fmt.Fprintln(stderr, line)
+	}
+}
+
+// dateLayouts are the accepted --date formats, without a zone the local
+// one is assumed
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// parseDate reads a --date value in one of dateLayouts
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(models.T(models.ErrInvalidDate), value)
+}
+
+// runStatus prints the repository status, as JSON for editor integrations
+func runStatus(gitService *timekeeper.Service, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, models.T("вывести статус в JSON"))
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var status *models.GitStatus
+	switch msg := gitService.LoadStatus().(type) {
+	case *models.GitStatus:
+		status = msg
+	case models.GitNotInitializedMsg:
+		fmt.Fprintln(stderr, msg.Message)
+		return 1
+	case models.ErrMsg:
+		fmt.Fprintf(stderr, models.T("Ошибка: %v\n"), timekeeper.Explain(msg.Error))
+		return 1
+	default:
+		fmt.Fprintf(stderr, models.T("Ошибка: неожиданный ответ %T\n"), msg)
+		return 1
+	}
+
+	if !*asJSON {
+		state := models.T(models.TextDirty)
+		if status.IsClean {
+			state = models.T(models.TextClean)
+		}
+		branch := status.Branch
+		if status.Ahead > 0 || status.Behind > 0 {
+			branch += fmt.Sprintf(" (↑%d ↓%d)", status.Ahead, status.Behind)
+		}
+		fmt.Fprintf(stdout, "%s %s\n%s\n", models.T(models.LabelBranch), branch, state)
+		return 0
+	}
+
+	// Empty lists stay arrays so consumers don't have to handle null
+	for _, list := range []*[]string{&status.Staged, &status.Modified, &status.Untracked, &status.Deleted, &status.Noise, &status.Large} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if status.Renamed == nil {
+		status.Renamed = []models.Rename{}
+	}
+	if status.Submodules == nil {
+		status.Submodules = []models.SubmoduleInfo{}
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(status); err != nil {
+		fmt.Fprintf(stderr, models.T("Ошибка: %v\n"), err)
+		return 1
+	}
+	return 0
+}
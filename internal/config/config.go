@@ -0,0 +1,410 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"time-machine/internal/models"
+)
+
+// File names of the global and per-repository config files
+const (
+	DirName      = "vibegit"
+	FileName     = "config.json"
+	RepoFileName = ".vibegit.json"
+)
+
+// Config holds the user settings
+type Config struct {
+	Status     StatusConfig     `json:"status"`
+	Sync       SyncConfig       `json:"sync"`
+	Shell      ShellConfig      `json:"shell"`
+	Author     AuthorConfig     `json:"author"`
+	Checkpoint CheckpointConfig `json:"checkpoint"`
+	UI         UIConfig         `json:"ui"`
+	Navigation NavigationConfig `json:"navigation"`
+	Hooks      HooksConfig      `json:"hooks"`
+	Rollback   RollbackConfig   `json:"rollback"`
+
+	// Profile names the preset from Profiles that is active on start
+	Profile string `json:"profile"`
+	// Profiles are named presets, each a partial config laid over the rest
+	Profiles map[string]json.RawMessage `json:"profiles"`
+}
+
+// AuthorConfig overrides the git identity used for commits made on the
+// user's behalf
+type AuthorConfig struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// CheckpointConfig tunes how checkpoints are made
+type CheckpointConfig struct {
+	// Chain adds a trailer hashing the previous checkpoint to every new one,
+	// so tampering with the history can be detected without GPG keys
+	Chain bool `json:"chain"`
+	// DefaultMessage is used when no description is typed. {date}, {time}
+	// and {branch} are replaced with their current values.
+	DefaultMessage string `json:"defaultMessage"`
+	// Suggestions replace the built-in moods offered for the description,
+	// an empty list keeps them
+	Suggestions []string `json:"suggestions"`
+	// AutoMinutes saves the changes on its own every that many minutes, 0
+	// turns auto-saving off
+	AutoMinutes int `json:"autoMinutes"`
+	// AutoExclude lists path patterns, matched like the noise ones, whose
+	// changes alone don't trigger an auto-save
+	AutoExclude []string `json:"autoExclude"`
+	// IncludeUntracked adds new files to checkpoints, when off only changes
+	// to files git already tracks are saved
+	IncludeUntracked bool `json:"includeUntracked"`
+	// ConfirmFiles asks before saving a checkpoint touching more files than
+	// this, 0 never asks
+	ConfirmFiles int `json:"confirmFiles"`
+}
+
+// IsAutoExcluded reports whether changes to file alone shouldn't trigger
+// an auto-save
+func (c CheckpointConfig) IsAutoExcluded(file string) bool {
+	return matchesAny(c.AutoExclude, file)
+}
+
+// StatusConfig tunes the status screen
+type StatusConfig struct {
+	// Noise lists path patterns of files that constantly change (lockfiles,
+	// generated code). They are shown muted and don't make the tree dirty.
+	// Patterns without a slash match the file name in any directory.
+	Noise []string `json:"noise"`
+	// Compact always shows the status as a single line, not only on short
+	// terminals
+	Compact bool `json:"compact"`
+	// LargeFileMB is the size above which tracked files are compared by
+	// size and modification time instead of content, 0 always reads them
+	LargeFileMB int64 `json:"largeFileMB"`
+}
+
+// IsNoise reports whether path matches one of the noise patterns
+func (c StatusConfig) IsNoise(file string) bool {
+	return matchesAny(c.Noise, file)
+}
+
+// matchesAny reports whether file matches one of the patterns. Patterns
+// without a slash match the file name in any directory.
+func matchesAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		target := file
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(file)
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncConfig tunes the sync with the remote
+type SyncConfig struct {
+	// AutoResolveConflicts commits local changes and keeps them over the
+	// remote ones when a pull can't be applied
+	AutoResolveConflicts bool `json:"autoResolveConflicts"`
+	// Retries is how many times a pull or push is repeated after a network failure
+	Retries int `json:"retries"`
+	// RetryDelayMs is the first pause between retries, doubled on every attempt
+	RetryDelayMs int `json:"retryDelayMs"`
+	// Remote is the name of the remote to sync with
+	Remote string `json:"remote"`
+	// ForcePush allows overwriting the remote when a normal push is
+	// rejected. Off by default, it discards whatever others pushed meanwhile.
+	ForcePush bool `json:"forcePush"`
+	// ProtectedBranches are never force pushed, whatever ForcePush says
+	ProtectedBranches []string `json:"protectedBranches"`
+	// PushNotes sends the checkpoint notes along with the branch
+	PushNotes bool `json:"pushNotes"`
+	// Strategy decides what a sync does when both sides have new commits,
+	// one of the Strategy constants
+	Strategy string `json:"strategy"`
+}
+
+// Sync strategies for branches that diverged: merge treats it as a
+// conflict, rebase replays the local commits on top of the remote ones and
+// ff-only stops without touching anything
+const (
+	StrategyMerge  = "merge"
+	StrategyRebase = "rebase"
+	StrategyFFOnly = "ff-only"
+)
+
+// CanForcePush reports whether branch may be force pushed
+func (c SyncConfig) CanForcePush(branch string) bool {
+	if !c.ForcePush {
+		return false
+	}
+	for _, protected := range c.ProtectedBranches {
+		if protected == branch {
+			return false
+		}
+	}
+	return true
+}
+
+// UIConfig tunes how the interface is drawn
+type UIConfig struct {
+	// Emoji draws emoji and glyphs, false swaps them for plain text markers.
+	// Unset guesses from the terminal.
+	Emoji *bool `json:"emoji"`
+	// RelativeDatesDays is how old a checkpoint can be for the history to
+	// show its date as "2 часа назад", older ones get the full date.
+	// 0 starts with full dates, D in the history then makes all relative.
+	RelativeDatesDays int `json:"relativeDatesDays"`
+}
+
+// NavigationConfig tunes moving through lists
+type NavigationConfig struct {
+	// Wrap jumps from the last menu or history item to the first and back
+	// instead of stopping
+	Wrap bool `json:"wrap"`
+}
+
+// Lifecycle points hooks run at
+const (
+	HookAfterCheckpoint = "afterCheckpoint"
+	HookAfterSync       = "afterSync"
+	HookBeforeQuit      = "beforeQuit"
+)
+
+// HooksConfig lists shell commands run at lifecycle points, e.g. to notify
+// someone or run a linter. A failing hook never fails the operation.
+type HooksConfig struct {
+	AfterCheckpoint []string `json:"afterCheckpoint"`
+	AfterSync       []string `json:"afterSync"`
+	BeforeQuit      []string `json:"beforeQuit"`
+	// ShowOutput shows what the hooks print, failures are shown anyway
+	ShowOutput bool `json:"showOutput"`
+}
+
+// Commands returns the hooks registered for event
+func (c HooksConfig) Commands(event string) []string {
+	switch event {
+	case HookAfterCheckpoint:
+		return c.AfterCheckpoint
+	case HookAfterSync:
+		return c.AfterSync
+	case HookBeforeQuit:
+		return c.BeforeQuit
+	}
+	return nil
+}
+
+// RollbackConfig tunes rolling back to a checkpoint
+type RollbackConfig struct {
+	// AutoSaveBefore checkpoints unsaved changes before a rollback throws
+	// them away
+	AutoSaveBefore bool `json:"autoSaveBefore"`
+}
+
+// ShellConfig tunes the drop-to-shell escape hatch
+type ShellConfig struct {
+	// Command runs instead of an interactive shell, e.g. "lazygit"
+	Command string `json:"command"`
+}
+
+// Default returns the settings used when no config file exists
+func Default() Config {
+	return Config{
+		Status: StatusConfig{
+			LargeFileMB: 50,
+		},
+		Checkpoint: CheckpointConfig{
+			IncludeUntracked: true,
+			ConfirmFiles:     200,
+		},
+		Rollback: RollbackConfig{
+			AutoSaveBefore: true,
+		},
+		UI: UIConfig{
+			RelativeDatesDays: 7,
+		},
+		Sync: SyncConfig{
+			Retries:      3,
+			RetryDelayMs: 1000,
+			Remote:       "origin",
+			Strategy:     StrategyMerge,
+		},
+	}
+}
+
+// ProfileNames returns the names of the defined profiles in sorted order
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile returns a copy of the config with the named profile laid over
+// it. An empty name returns the config as is.
+func (c Config) WithProfile(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	overlay, ok := c.Profiles[name]
+	if !ok {
+		return c, fmt.Errorf(models.T("профиль %q не найден"), name)
+	}
+
+	// Round-trip through JSON for a deep copy, so the overlay can't touch
+	// slices shared with c
+	data, err := json.Marshal(c)
+	if err != nil {
+		return c, err
+	}
+	var profiled Config
+	if err := json.Unmarshal(data, &profiled); err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(overlay, &profiled); err != nil {
+		return c, fmt.Errorf(models.T("профиль %q: %w"), name, err)
+	}
+
+	profiled.Profile = name
+	profiled.Profiles = c.Profiles
+	return profiled, nil
+}
+
+// Dir returns the directory holding the global config and state files
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, DirName), nil
+}
+
+// Load reads the global config and overlays the config of the repository
+// in repoDir. Missing files are skipped, the defaults are returned along
+// with the error when a file can't be parsed.
+func Load(repoDir string) (Config, error) {
+	cfg := Default()
+
+	if dir, err := Dir(); err == nil {
+		if err := loadFile(filepath.Join(dir, FileName), &cfg); err != nil {
+			return Default(), err
+		}
+	}
+
+	if repoDir != "" {
+		if err := loadRepoFile(filepath.Join(repoDir, RepoFileName), &cfg); err != nil {
+			return Default(), err
+		}
+	}
+
+	// The active profile must exist, it is applied by whoever uses the config
+	if _, err := cfg.WithProfile(cfg.Profile); err != nil {
+		return Default(), err
+	}
+
+	return cfg, nil
+}
+
+// untrustedKeys are the settings that run commands or let the tool throw
+// away work, nested ones with a dot. A repository file comes with whatever
+// was cloned, so they are only read from the global config.
+var untrustedKeys = []string{"hooks", "shell", "sync.forcePush", "sync.autoResolveConflicts", "rollback.autoSaveBefore"}
+
+// loadFile decodes the JSON file at path on top of cfg
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// loadRepoFile decodes the repository config at path on top of cfg, leaving
+// out the untrusted keys at the top level and in every profile it defines
+func loadRepoFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, key := range untrustedKeys {
+		if err := dropKey(fields, key); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if raw, ok := fields["profiles"]; ok {
+		var profiles map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &profiles); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, profile := range profiles {
+			for _, key := range untrustedKeys {
+				if err := dropKey(profile, key); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+		}
+		if fields["profiles"], err = json.Marshal(profiles); err != nil {
+			return err
+		}
+	}
+
+	if data, err = json.Marshal(fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// dropKey removes key from fields, descending into the objects a dotted key
+// names. Fields that aren't objects are left for decoding to complain about.
+func dropKey(fields map[string]json.RawMessage, key string) error {
+	head, rest, nested := strings.Cut(key, ".")
+	if !nested {
+		delete(fields, key)
+		return nil
+	}
+	raw, ok := fields[head]
+	if !ok {
+		return nil
+	}
+	var inner map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &inner); err != nil {
+		return nil
+	}
+	if err := dropKey(inner, rest); err != nil {
+		return err
+	}
+	data, err := json.Marshal(inner)
+	if err != nil {
+		return err
+	}
+	fields[head] = data
+	return nil
+}
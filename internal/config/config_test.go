@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadWith writes the global config and the repository's .vibegit.json,
+// empty for none, to temporary directories and loads them
+func loadWith(t *testing.T, global, repo string) Config {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	if global != "" {
+		dir, err := Dir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, FileName), []byte(global), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repoDir := t.TempDir()
+	if repo != "" {
+		if err := os.WriteFile(filepath.Join(repoDir, RepoFileName), []byte(repo), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := Load(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestRepoFileCannotForcePush(t *testing.T) {
+	tests := []struct {
+		name   string
+		global string
+		repo   string
+		want   bool
+	}{
+		{
+			name: "repository file",
+			repo: `{"sync": {"forcePush": true, "remote": "upstream"}}`,
+		},
+		{
+			name: "repository profile",
+			repo: `{"profile": "fast", "profiles": {"fast": {"sync": {"forcePush": true}}}}`,
+		},
+		{
+			name:   "global config",
+			global: `{"sync": {"forcePush": true}}`,
+			repo:   `{"sync": {"remote": "upstream"}}`,
+			want:   true,
+		},
+		{
+			name:   "global config the repository turns off",
+			global: `{"sync": {"forcePush": true}}`,
+			repo:   `{"sync": {"forcePush": false}}`,
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadWith(t, tt.global, tt.repo)
+			profiled, err := cfg.WithProfile(cfg.Profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if profiled.Sync.ForcePush != tt.want {
+				t.Errorf("ForcePush = %v, want %v", profiled.Sync.ForcePush, tt.want)
+			}
+		})
+	}
+
+	// The rest of the section still comes from the repository
+	if cfg := loadWith(t, "", `{"sync": {"forcePush": true, "remote": "upstream"}}`); cfg.Sync.Remote != "upstream" {
+		t.Errorf("Remote = %q, want the repository's upstream", cfg.Sync.Remote)
+	}
+}
+
+func TestRepoFileCannotRiskWork(t *testing.T) {
+	repo := `{
+		"sync": {"autoResolveConflicts": true},
+		"rollback": {"autoSaveBefore": false},
+		"profile": "risky",
+		"profiles": {"risky": {"sync": {"autoResolveConflicts": true}, "rollback": {"autoSaveBefore": false}}}
+	}`
+	cfg, err := loadWith(t, "", repo).WithProfile("risky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Sync.AutoResolveConflicts {
+		t.Error("the repository file turned on resolving conflicts automatically")
+	}
+	if !cfg.Rollback.AutoSaveBefore {
+		t.Error("the repository file turned off saving before a rollback")
+	}
+
+	global := `{"sync": {"autoResolveConflicts": true}, "rollback": {"autoSaveBefore": false}}`
+	cfg = loadWith(t, global, "")
+	if !cfg.Sync.AutoResolveConflicts || cfg.Rollback.AutoSaveBefore {
+		t.Errorf("the global config isn't honored: %+v %+v", cfg.Sync, cfg.Rollback)
+	}
+}
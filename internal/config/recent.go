@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// RecentFileName keeps the recently opened repositories
+const RecentFileName = "recent.json"
+
+// recentLimit caps how many repositories are remembered
+const recentLimit = 10
+
+// LoadRecent returns the recently opened repository paths, most recent
+// first. A missing or broken file yields an empty list.
+func LoadRecent() []string {
+	dir, err := Dir()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, RecentFileName))
+	if err != nil {
+		return nil
+	}
+	var recent []string
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return nil
+	}
+	return recent
+}
+
+// RememberRecent moves path to the front of the recent repositories
+func RememberRecent(path string) error {
+	recent := []string{path}
+	for _, known := range LoadRecent() {
+		if known != path && len(recent) < recentLimit {
+			recent = append(recent, known)
+		}
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(recent, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, RecentFileName), data, 0o644)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// StateFileName is where choices made in the interface are remembered
+const StateFileName = "state.json"
+
+// State holds what the user chose in the interface, as opposed to Config
+// which they edit by hand
+type State struct {
+	// Compact is the preferred status layout, nil until the user picks one
+	Compact *bool `json:"compact,omitempty"`
+	// IdentityDismissed hides the missing git identity banner for good
+	IdentityDismissed bool `json:"identityDismissed,omitempty"`
+	// HideMenuHelp drops the explanation under the selected menu item
+	HideMenuHelp bool `json:"hideMenuHelp,omitempty"`
+}
+
+// LoadState reads the remembered state. A missing or broken file yields an
+// empty state, it is only a convenience.
+func LoadState() State {
+	var state State
+	dir, err := Dir()
+	if err != nil {
+		return state
+	}
+	data, err := os.ReadFile(filepath.Join(dir, StateFileName))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}
+	}
+	return state
+}
+
+// SaveState writes the state to the config dir
+func SaveState(state State) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, StateFileName), data, 0o644)
+}
@@ -0,0 +1,497 @@
+package models
+
+// english translates the Russian texts, see T
+var english = map[string]string{
+	// Activity log
+	ActivityCheckpoint: "Save",
+	ActivityRollback:   "Rollback",
+	ActivitySync:       "Sync",
+	ActivityForcePush:  "Force sync",
+	ActivityConflict:   "Conflict",
+	ActivitySquash:     "Squash",
+	ActivityMerge:      "Merge",
+	ActivityBranch:     "Branch",
+	ActivityUndo:       "Undo save",
+	ActivityRevert:     "Revert keeping history",
+
+	// Ref actions and conflict choices
+	"Вернуть этот вайб":                                        "Bring this vibe back",
+	"Посмотреть, что поменялось в этом сейве":                  "See what this save changed",
+	"Полистать историю этой ветки, не переключаясь":            "Browse this branch's history without switching",
+	"Оставить мои изменения (облако перезапишется)":            "Keep my changes (the cloud gets overwritten)",
+	"Взять версию из облака (мои незапушенные сейвы пропадут)": "Take the cloud version (my unpushed saves are lost)",
+	"Отмена, разберусь сам":                                    "Cancel, I'll sort it out myself",
+
+	// Menu
+	MenuInitGit:          "Start a vibe session",
+	MenuCreateCheckpoint: "Save Vibe",
+	MenuViewHistory:      "Flow History",
+	MenuRollback:         "Bring back a past vibe",
+	MenuSync:             "Sync with the cloud",
+	MenuSaveAndSync:      "Save + Sync",
+	MenuViewChanges:      "View changes",
+	MenuCreateMarker:     "Drop a marker in the history",
+	MenuSquash:           "Squash saves before pushing",
+	MenuUpdateSubmodules: "Update submodules",
+	MenuMergeContinue:    "Finish the merge",
+	MenuMergeAbort:       "Abort the merge",
+	MenuReturnToPresent:  "Back to the present",
+	MenuBranches:         "Branches",
+
+	// Menu descriptions
+	"Создаст git-репозиторий в этой папке, чтобы было куда сейвить":                   "Creates a git repository in this folder so there is somewhere to save",
+	"Запомнит текущее состояние файлов, к нему всегда можно вернуться":                "Remembers the current state of the files, you can always come back to it",
+	"Покажет все сейвы: что, когда и кто, с диффом каждого":                           "Shows every save: what, when and who, with the diff of each",
+	"Вернёт файлы к одному из прошлых сейвов, сначала покажет, что изменится":         "Brings the files back to a past save, showing what will change first",
+	"Отправит твои сейвы в облако и заберёт чужие":                                    "Sends your saves to the cloud and fetches everyone else's",
+	"Засейвит и сразу синканёт, одним заходом":                                        "Saves and syncs right away, in one go",
+	"Покажет, что изменилось с последнего сейва":                                      "Shows what changed since the last save",
+	"Пустой сейв-закладка, например «начало рефакторинга»":                            "An empty bookmark save, like \"refactoring starts here\"",
+	"Склеит автоматические сейвы в один, чтобы история в облаке была чище":            "Glues automatic saves into one to keep the cloud history clean",
+	"Скачает версии субмодулей, записанные в проекте":                                 "Downloads the submodule versions recorded in the project",
+	"Сохранит слияние с тем, как ты разрешил конфликты в файлах":                      "Saves the merge with the conflicts resolved the way you did in the files",
+	"Вернёт файлы как до слияния, правки после его начала пропадут":                   "Puts the files back as before the merge, edits made since it started are lost",
+	"Отменит откаты: ветка снова будет там, где была до первого из них":               "Undoes the rollbacks: the branch goes back to where it was before the first one",
+	"Переключит на другую ветку или заведёт новую, чтобы экспериментировать отдельно": "Switches to another branch or starts a new one to experiment separately",
+
+	// UI texts
+	TitleDescription:         " VibeGit [Saving the vibe] ",
+	TitleMarker:              " VibeGit [History marker] ",
+	TitleSquash:              " VibeGit [Squashing saves: %d] ",
+	TitleWorkingTreeDiff:     "Unsaved changes",
+	TitleBlame:               "Who wrote %s as of save %.7s",
+	PromptBlame:              "Which file to show as of save %.7s? Path from the project root:",
+	HelpBlame:                "[Enter Show] [Esc Cancel]",
+	PromptNote:               "Note for save %.7s (empty to remove):",
+	HelpNote:                 "[Enter Save] [Esc Cancel]",
+	TitleWorkingTreeSince:    "What changed since save %.7s: %s",
+	TitleCheckpointDiff:      "Save %.7s: %s",
+	TitleCompareCheckpoints:  "What changed from save %.7s to save %.7s",
+	PromptDescription:        "Describe this moment of the flow:",
+	PromptSuggestions:        "💡 Or pick a mood:",
+	HelpMain:                 "↑↓ Navigate | Enter Select | ? Hints | q Quit",
+	HelpHotkeys:              "Hotkeys: [C] Save [H] History [R] Reset [S] Sync [P] Save+Sync [D] Diff [M] Marker [F] Files [G] Go to save [/] Find and roll back [U] Undo save [Z] Stash [B] Branches [W] Projects [L] Log [O] Profile [V] View [!] Terminal",
+	HelpDescription:          "[Enter Save] [Esc Cancel] [1-9 Quick pick]",
+	HelpHistory:              "↑↓ Scroll | PgUp/PgDn Page | Enter Bring this vibe back | I Details | S What the save changed | W What changed since | B Who wrote a file | Shift+B Branch from here | P Pin | N Note | T Tag | Space Compare base | D Dates or compare | C Collapse saves | E Expand group | V Verify chain | / Filter | Esc Back",
+	TextCheckpointGroup:      "%d %s 🌊 %s — %s",
+	TextHistoryMoreAbove:     "▲ %d more",
+	TextHistoryMoreBelow:     "▼ %d more",
+	TextHistoryOlder:         "▼ older saves load as you scroll",
+	TextHistoryLoadingOlder:  "▼ loading older saves...",
+	HelpStaging:              "↑↓ Scroll | Space Select | A All/none | N New files | Enter Next | Esc Cancel",
+	TextUntrackedOn:          "New files go into the save",
+	TextUntrackedOff:         "New files stay out of the save",
+	HelpDiff:                 "↑↓ Scroll | PgUp/PgDn Page | Esc Back",
+	HelpWorkingTreeDiff:      "↑↓ Scroll | PgUp/PgDn Page | S Into the save | U Out of the save | Esc Back",
+	LabelDiffFile:            "File: %s",
+	TextDiffFileStaged:       " ✓ in the save",
+	HelpFiles:                "↑↓ Scroll | U Take out of the save | Shift+U Take everything out | R Restore deleted | Esc Back",
+	TextFileRestored:         "File restored: %s",
+	HelpConflict:             "↑↓ Choose | Enter Confirm | Esc I'll sort it out",
+	HelpProfiles:             "↑↓ Choose | Enter Switch on | Esc Back",
+	HelpActivity:             "↑↓ Scroll | Esc Back",
+	LabelActivity:            "Log: what VibeGit did to your projects",
+	TextNoActivity:           "The log is empty",
+	TextNoStashes:            "Nothing stashed",
+	TextStashApplied:         "Stash stash@{%d} applied",
+	TextStashPopped:          "Stash stash@{%d} applied and removed from the list",
+	TextStashDropped:         "Stash stash@{%d} dropped",
+	LabelStashes:             "Stashed (git stash):",
+	LabelBranches:            "Branches:",
+	TextBranchSwitched:       "You're on branch %s",
+	TextBranchCreated:        "Branch %s created, you're on it",
+	TextBranchCreatedFrom:    "Branch %s starts at save %.7s, you're on it",
+	PromptBranchFrom:         "Name of the new branch starting at save %.7s:",
+	PromptTag:                "Tag for save %.7s:",
+	TextAlreadyOnBranch:      "You're already on branch %s",
+	PromptBranch:             "Name of the new branch (starts at the current save):",
+	HelpBranches:             "↑↓ Choose | Enter Switch | N New branch | Esc Back",
+	HelpBranchInput:          "[Enter Create] [Esc Cancel]",
+	HelpTagInput:             "[Enter Tag] [Esc Cancel]",
+	HelpStashes:              "↑↓ Scroll | A Apply | P Apply and remove | X Drop | Esc Back",
+	TextHistoryProgress:      "Saves loaded: %d (Esc to stop)",
+	TextHistoryCanceled:      "History loading stopped, showing the latest %d saves",
+	TextHistorySummary:       "Saves: %d · from %s to %s",
+	TextHistoryPartSummary:   "Saves loaded: %d · from %s to %s",
+	TextHistoryUnpushed:      " · not in the cloud: %d",
+	TextUnpushedCheckpoints:  "You have %d %s %s",
+	TextShallowHistory:       "The history is cut short (shallow clone). [U] Fetch the whole history",
+	TextUnshallowed:          "The whole history is fetched",
+	TextStillShallow:         "Part of the history still isn't fetched",
+	HelpRefInput:             "[Enter Find] [Esc Cancel]",
+	HelpRefActions:           "↑↓ Choose | Enter Go | Esc Back",
+	PromptSearch:             "What are we looking for? Words from the description, author, tag or hash:",
+	HelpSearch:               "[↑↓ Choose] [Enter Roll back] [Esc Cancel]",
+	TextNoMatches:            "Nothing found",
+	PromptRef:                "Where to? Hash, branch, tag or HEAD~3:",
+	LabelProfiles:            "Settings profiles:",
+	LabelProfile:             "Profile:",
+	LabelConflict:            "⚡ You and the cloud went separate ways. What now?",
+	LabelActions:             "What now:",
+	LabelHistory:             "Your flow:",
+	LabelBranchHistory:       "Flow of branch %s (read-only):",
+	TextHistoryReadOnly:      "This is another branch's history: switch to it to roll back or change saves",
+	LabelFiles:               "Changed files:",
+	LabelBranch:              "Branch:",
+	LabelScope:               "Folder: %s",
+	TextScopeHidden:          " (more files outside it: %d, A for the whole project)",
+	TextScopeWhole:           " (A for this folder only)",
+	LabelLastCommit:          "Last save:",
+	LabelStaged:              "Ready to save:",
+	LabelModified:            "Changed:",
+	LabelUntracked:           "New:",
+	LabelRenamed:             "Renamed:",
+	LabelDeleted:             "Deleted:",
+	LabelStaging:             "What goes into the save:",
+	LabelSubmodules:          "Submodules:",
+	TextNoCheckpoints:        "No vibes yet, start creating",
+	TextNoFiles:              "No changes, everything is saved",
+	TextSelectedCount:        "%d selected",
+	TextNoDiff:               "No changes",
+	TextNoCommits:            "No moments",
+	TextNothingToSave:        "Nothing to save: no changes. Need a marker in the history? Press [M]",
+	TextNothingToSquash:      "Nothing to squash: it takes at least two saves in a row after the last manual commit or pinned save",
+	TextWorkDirGone:          "There's nowhere left to work. Press q to quit",
+	TextIdentityMissing:      "Git doesn't know who you are: user.name and user.email aren't set, saves will go out under someone else's name",
+	HelpIdentityBanner:       "[I] Introduce yourself [X] Don't show again",
+	TextMergeInProgress:      "A merge is in progress: resolve the conflicts in the files and finish it, or abort it",
+	TextMergeCommitted:       "Merge saved: %.7s",
+	TextMergeAborted:         "Merge aborted, the files are as before it",
+	TextReturnedToPresent:    "Back in the present: %.7s",
+	TextConfirmCheckpoint:    "The save touches %d files (the limit is %d). Really save all of it?",
+	HelpConfirmCheckpoint:    "[Y/Enter] Save [N/Esc] Cancel",
+	TextConfirmCheckpointCLI: "The save touches %d files, more than checkpoint.confirmFiles (%d). If that's intended, add --yes",
+	TextCheckpointCanceled:   "Save canceled",
+	TextHookFailed:           "Hook %q failed: %s",
+	TextHookOutput:           "Hook %q: %s",
+	TextAutoCheckpointTooBig: "Auto-save skipped: %d files changed, more than the limit. Save by hand if that's right",
+	TextInThePast:            "You're in the past: the branch is rolled back from %.7s. [N] Back to the present",
+	PromptIdentityName:       "What name should git sign you with (user.name)?",
+	PromptIdentityEmail:      "Your email for git (user.email)?",
+	HelpIdentityInput:        "[Enter Next] [Esc Cancel]",
+	TextIdentitySet:          "Git knows you now: %s <%s>",
+	LabelRecent:              "Recent projects:",
+	HelpRecent:               "↑↓ Choose | Enter Open | Esc Back",
+	TextNoRecent:             "No other projects yet",
+	TextRepositorySwitched:   "Opened project %s",
+	TextAutoCheckpoint:       "Auto-save: ",
+	TextStateNotSaved:        "The view wasn't remembered: %v",
+	TextShellFailed:          "The terminal exited with an error: %v",
+	TextEstimatedSize:        "This save adds ~%s",
+	TextStagedOnlyHint:       "[S] Save only what's staged (%d)",
+	TextMoreSuggestions:      "No quick pick, type it in: %s",
+	TextNoProfile:            "no profile",
+	TitleSummaryRollback:     "Here's what happened: rollback",
+	TitleSummarySquash:       "Here's what happened: squash",
+	TitleSummaryMerge:        "Here's what happened: merge",
+	TitleSummaryForcePush:    "Here's what happened: force push",
+	TitleSummaryUndo:         "Here's what happened: save undone",
+	TextCheckpointUndone:     "Save \"%s\" undone, its changes are still in the files",
+	TextReverted:             "The files are as in save %.7s, the revert is recorded as save %.7s, the history stays",
+	TextRollbackBackup:       "The edits from before the rollback are saved on branch %s: [N] brings them back until you save again, and the branch keeps them after that",
+	TextTakeTheirsBackup:     "The unsaved edits are kept on branch %s, [B] switches to it",
+	TextNothingToRevert:      "The files are already as in save %.7s",
+	TextNothingToUndo:        "Nothing to undo: this is the very first save",
+	TextSummaryHeads:         "Was %.7s → now %.7s",
+	TextSummaryAffected:      "No longer in the history: %d",
+	TextSummaryMore:          "…and %d more",
+	TextConflictOverwrites:   "Keeping your version removes these saves from the cloud (%d):",
+	TextConflictNoForce:      "Keeping your version isn't offered: it would overwrite the cloud, and force pushing is off (sync.forcePush)",
+	TextForcePushing:         "The normal push failed, force pushing, saves overwritten in the cloud: %d...",
+	HelpSummary:              "Any key to close",
+	LabelSyncResult:          "Sync with the cloud:",
+	TextSyncReceived:         "received ↓%d",
+	TextSyncSent:             "sent ↑%d",
+	TextSyncInSync:           "everything matches",
+	TextSyncForced:           " (forced)",
+	TextForcePushOverwrote:   "overwrote %d remote moments, the previous cloud version is %.7s",
+	TextRebased:              "Copy received, own saves replayed on top: %d",
+	TextSyncStrategy:         " · strategy: %s",
+	TextSyncOverwritten:      "⚠ overwrote %d %s %s, the previous cloud version is %.7s",
+	TextSyncResolved:         "conflict resolved in favor of your version",
+	LabelSyncLocal:           "local",
+	LabelSyncRemote:          "cloud",
+	TitlePreview:             "Roll back to %.7s: %s?",
+	TextPreviewCounts:        "Changed: %d, restored: %d, deleted: %d",
+	TextPreviewCommits:       "Saves dropped from the branch history: %d",
+	TextPreviewNothing:       "The files won't change",
+	TextChainIntact:          "The chain is intact: %d saves checked",
+	TextChainEmpty:           "No saves in the history have a chain, turn on checkpoint.chain in the settings",
+	TextPreviewUnpushed:      "%d moments not yet in the cloud will be lost",
+	HelpPreview:              "Enter/Y Rewind (erase) | R Revert (keep history) | Esc/N Cancel",
+	HelpPreviewUnsaved:       "[y/n] Y Rewind, losing the edits | R Revert (keep history) | Esc/N Cancel",
+	TextPreviewUnsavedLost:   "Go back to this vibe? Unsaved changes will be lost (%d files)",
+	TextPreviewUnsavedSaved:  "Unsaved changes (%d files) will be saved first as \"Before rolling back\"",
+	TextNoProfiles:           "No profiles: add them to \"profiles\" in the settings",
+	TextProfileActive:        "Profile: %s",
+	TextDiffPosition:         "lines %d-%d of %d",
+	TextCurrent:              " (current vibe)",
+	TextNoteSaved:            "Note for save %.7s saved",
+	TextTagged:               "Save %.7s tagged %s",
+	TextCompareBaseMarker:    " [base]",
+	TextCompareHint:          "Compare base %.7s: pick another save and press D, Space clears the mark",
+	TextCompareSame:          "This is the compare base, pick another save",
+	TextNoteRemoved:          "Note for save %.7s removed",
+	TextNotesNotPushed:       "notes not pushed: %v",
+	LabelNote:                "Note:",
+	TextPinned:               "Save %.7s pinned: squashing won't touch it",
+	TextUnpinned:             "Save %.7s is no longer pinned",
+	TextClean:                "✓ You're in the flow. All clean.",
+	TextDirty:                "⚡ There's unsaved progress",
+	TextLoading:              "Working on it: ",
+	TextSubmodulesWarning:    "⚠ Submodule contents don't go into saves",
+	TextSubmoduleNotInit:     " (not initialized)",
+	TextLargeFile:            " (large file, compared by size and date)",
+	TextSubmoduleChanged:     " (different commit)",
+	TextSubmodulesUpdated:    "Submodules updated",
+
+	// Errors
+	ErrFailedToAddFiles:           "failed to add files",
+	ErrFailedToCreateCheckpoint:   "failed to save the moment",
+	ErrFailedToOpenRepo:           "failed to open the project",
+	ErrFailedToGetWorktree:        "failed to get the working folder",
+	ErrFailedToGetStatus:          "failed to get the status",
+	ErrFailedToGetHead:            "failed to get the current moment",
+	ErrFailedToRevert:             "failed to revert keeping the history",
+	ErrRevertUnsaved:              "the revert would overwrite files with unsaved edits, save them first: %s",
+	ErrFailedToUndo:               "failed to undo the save",
+	ErrUndoPinned:                 "the save %.7s is pinned 📌: unpin it (P in the history) to undo it",
+	ErrFailedToCommit:             "failed to save the conflict resolution",
+	ErrFailedToAddChanges:         "failed to add the changes",
+	ErrFailedToPush:               "failed to send the copy",
+	ErrFailedToPull:               "failed to fetch changes from the cloud",
+	ErrFailedToUpdateSubmodules:   "failed to update the submodules",
+	ErrFailedToUnstage:            "failed to take files out of the save",
+	ErrFailedToRestoreFile:        "failed to restore the file",
+	ErrFailedToStage:              "failed to add files to the save",
+	ErrFailedToBuildDiff:          "failed to collect the changes",
+	ErrLinkedWorktreeUnsupported:  "linked working trees (git worktree) aren't supported",
+	ErrFailedToSquash:             "failed to squash the saves",
+	ErrFailedToPin:                "failed to pin the save",
+	ErrFailedToTag:                "failed to tag the save",
+	ErrHookTimeout:                "the hook didn't finish within 30 seconds and was stopped",
+	ErrFailedToReturn:             "failed to return to the present",
+	ErrNoPresent:                  "nowhere to return to: you're already in the present",
+	ErrPresentUnsaved:             "there are unsaved edits that returning would lose. Save them or roll them back",
+	ErrFailedToSaveBeforeRollback: "failed to save the edits before rolling back, the rollback is canceled",
+	ErrFailedToMerge:              "failed to finish the merge",
+	ErrFailedToAbortMerge:         "failed to abort the merge",
+	ErrNoMergeInProgress:          "no merge is in progress",
+	ErrMergeUnresolved:            "conflict markers (<<<<<<< / >>>>>>>) are left in the files: %s",
+	ErrFailedToSaveNote:           "failed to save the note",
+	ErrFailedToReadNotes:          "failed to read the notes",
+	ErrInvalidAuthorEmail:         "invalid author email",
+	ErrInvalidDate:                "couldn't understand the date %q, expected 2006-01-02, \"2006-01-02 15:04\" or RFC 3339",
+	ErrDateInFuture:               "the save date %s hasn't come yet",
+	ErrFailedToReadActivity:       "failed to read the log",
+	ErrFailedToPreview:            "failed to work out what the rollback would do",
+	ErrFailedToBlame:              "failed to collect who wrote the lines",
+	ErrFileNotInCheckpoint:        "file %s isn't in save %.7s",
+	ErrFailedToSetIdentity:        "failed to save the name in git",
+	ErrEmptyIdentityName:          "the name can't be empty",
+	ErrFailedToVerifyChain:        "failed to verify the chain of saves",
+	ErrChainBroken:                "the chain of saves is broken before %.7s (%s): the previous save was changed or replaced",
+	ErrFailedToReadStash:          "failed to read the stash",
+	ErrFailedToListBranches:       "failed to list the branches",
+	ErrFailedToSwitchBranch:       "failed to switch the branch",
+	ErrFailedToCreateBranch:       "failed to create the branch",
+	ErrSwitchBranchDirty:          "there are unsaved changes, save them before moving to %s",
+	ErrBranchExists:               "branch %q already exists",
+	ErrTagExists:                  "tag %q already exists, pick another name",
+	ErrSwitchBranchUntracked:      "the new file %s would be overwritten by the branch's file, save or move it",
+	ErrBranchWithoutCheckpoint:    "the branch has nothing to start from: make the first save",
+	ErrFailedToDropStash:          "failed to drop the stash",
+	ErrFailedToApplyStash:         "failed to apply the stash",
+	ErrStashNotFound:              "there is no stash@{%d}",
+	ErrStashWouldOverwrite:        "the stash would overwrite your unsaved changes, save them first",
+	ErrNotAStash:                  "this isn't a stash entry",
+	ErrFailedToUnshallow:          "failed to fetch the history",
+	ErrParentMissing:              "the previous save isn't fetched: the history is cut short (shallow clone), fetch it with U in the history",
+	ErrRefNotFound:                "couldn't find the save",
+	ErrInvalidBranchName:          "invalid branch name %q",
+	ErrInvalidTagName:             "invalid tag name %q",
+	ErrBranchNotFound:             "branch %q exists neither here nor in the cloud",
+	ErrRefAmbiguous:               "several saves start with %q, type more characters: %s",
+	ErrWorkDirUnavailable:         "The working folder is unavailable: it was deleted or is no longer accessible",
+	ErrDirNotFound:                "folder %s not found",
+	ErrNotADirectory:              "%s is not a folder",
+	ErrNoRemote:                   "No remote storage found. This is a local-only version.",
+	ErrRemoteNotFoundIn:           "There is no remote %q, only: %s. Set the right one in sync.remote",
+	ErrRemoteUnreachable:          "can't reach the cloud",
+	ErrRemoteBadCredentials:       "wrong credentials for the cloud",
+	ErrAlreadyUpToDate:            "Everything is up to date",
+	ErrConflictsDetected:          "Local changes saved over the remote ones",
+	ErrConflictManual:             "Sync canceled, resolve the conflict by hand. Files changed both on your side and in the cloud",
+	ErrConflictNoOverlap:          "Sync canceled, resolve the conflict by hand. No files changed on both sides",
+	ErrFailedToFetch:              "failed to fetch the cloud version",
+	ErrTookTheirs:                 "Took the cloud version",
+	ErrFailedToTakeTheirs:         "failed to switch to the cloud version",
+	ErrTakeTheirsUnsaved:          "there are unsaved edits the cloud version would wipe. Save them or turn on rollback.autoSaveBefore",
+	ErrForcePushSuccess:           "Copy force pushed",
+	ErrForcePushForbidden:         "The cloud doesn't accept the saves, and force pushing to this branch is disabled in the settings",
+	ErrPushRejected:               "The cloud doesn't accept the saves, and this sync strategy never overwrites it",
+	ErrPushRejectedNewer:          "The cloud doesn't accept the saves: it has newer saves you don't have (%d). A force push would overwrite them, so it's off — turn on sync.forcePush if that's what you want",
+	ErrSyncDiverged:               "There are new saves both here and in the cloud. The ff-only strategy leaves them be: pick merge or rebase in sync.strategy",
+	ErrRebaseClash:                "Sync canceled: the same files changed here and in the cloud, rebase doesn't combine them",
+	ErrFailedToRebase:             "failed to replay the saves on top of the cloud ones",
+	ErrRebaseUnsaved:              "save your changes before syncing: rebase only replays saves",
+	ErrRebaseUnrelated:            "your history and the cloud one have no common start",
+	ErrRebaseMerges:               "the new saves include a branch merge, rebase can't replay that",
+	ErrMoveUntracked:              "the new file %s is in the way, the saved version would overwrite it",
+	ErrUnknownStrategy:            "unknown sync strategy %q: use merge, rebase or ff-only",
+	ErrPushSuccess:                "Copy sent successfully",
+	ErrPullSuccess:                "Copy received successfully",
+
+	ErrCategoryNetwork: "Network",
+	ErrCategoryAuth:    "Access",
+	ErrCategoryRepo:    "Project",
+
+	ErrHintAuthRequired:   "the cloud requires authorization: check your SSH key or token",
+	ErrHintAuthFailed:     "the cloud denied access: the key or token has no rights to this repository",
+	ErrHintRemoteNotFound: "the repository isn't found in the cloud: check the remote address",
+	ErrHintRemoteEmpty:    "the repository in the cloud is still empty",
+	ErrHintUnreachable:    "the cloud is unreachable: check your internet, VPN or the server address",
+	ErrHintTimeout:        "the cloud didn't answer in time, try again",
+	ErrHintNotRepo:        "there's no git repository here",
+	ErrHintNoRemote:       "the project has no remote set up",
+	ErrHintRefNotFound:    "the branch or moment isn't found, it may have been deleted",
+	ErrHintObjectMissing:  "the repository is missing data, it may be damaged",
+	ErrHintNonFastForward: "the cloud history moved ahead, fetch the changes first",
+	ErrHintDirtyWorktree:  "there are unsaved changes, make a save first",
+	ErrHintEmptyCommit:    "nothing to save: no changes",
+
+	// Commit messages
+	DefaultCheckpointMessage: "Save without a description",
+	AutoCheckpointMessage:    "Auto-save",
+	PreRollbackMessage:       "Before rolling back to %.7s",
+	RevertCommitMessage:      "Revert to %.7s: %s",
+	MergeCommitMessage:       "Merge branches",
+
+	// Description suggestions
+	"Поймал волну 🌊":                  "Caught the wave 🌊",
+	"Фикс на лету 🐛":                  "Fix on the fly 🐛",
+	"Новая фича готова ✨":             "New feature ready ✨",
+	"Рефакторинг для души 🧹":          "Refactoring for the soul 🧹",
+	"Эксперименты с кодом 🧪":          "Code experiments 🧪",
+	"Просто сейв на всякий случай 🛡️": "Just a save, to be safe 🛡️",
+	"Красиво сделал 🎨":                "Made it pretty 🎨",
+	"Оптимизация 🚀":                   "Optimization 🚀",
+	"Тесты прошли ✅":                  "Tests pass ✅",
+	"Вайб чек 🤙":                      "Vibe check 🤙",
+	"Прогресс неостановим 🔥":          "Unstoppable progress 🔥",
+	"Магия кода 🪄":                    "Code magic 🪄",
+	"Дзен-код 🧘":                      "Zen code 🧘",
+	"Ещё один шаг к релизу 🎯":         "One more step to the release 🎯",
+
+	// Loading texts
+	"Синхронизирую потоки...":        "Syncing the flows...",
+	"Вспоминаем былое...":            "Recalling the past...",
+	"Достаю отложенное...":           "Getting the stash...",
+	"Отменяю сейв...":                "Undoing the save...",
+	"Листаю журнал...":               "Reading the log...",
+	"Разруливаю конфликт...":         "Sorting out the conflict...",
+	"Откатываю, сохраняя историю...": "Reverting, keeping the history...",
+	"Возвращаю старый вайб...":       "Bringing the old vibe back...",
+	"Сейвлю вайб...":                 "Saving the vibe...",
+	"Возвращаю отложенное...":        "Applying the stash...",
+	"Удаляю отложенное...":           "Dropping the stash...",
+	"Создаю ветку...":                "Creating the branch...",
+	"Переключаю ветку...":            "Switching the branch...",
+	"Ищу сейв...":                    "Looking for the save...",
+	"Прикидываю последствия...":      "Working out the consequences...",
+	"Вспоминаю, кто что писал...":    "Recalling who wrote what...",
+	"Открываю проект...":             "Opening the project...",
+	"Запоминаю тебя...":              "Remembering you...",
+	"Собираю изменения...":           "Collecting the changes...",
+	"Ловлю вдохновение...":           "Catching inspiration...",
+	"Убираю из сейва...":             "Taking it out of the save...",
+	"Возвращаю файл...":              "Restoring the file...",
+	"Схлопываю сейвы...":             "Squashing the saves...",
+	"Докачиваю историю...":           "Fetching the history...",
+	"Проверяю цепочку...":            "Verifying the chain...",
+	"Настраиваю пространство...":     "Setting up the space...",
+	"Ищу сейвы для схлопывания...":   "Looking for saves to squash...",
+	"Подтягиваю субмодули...":        "Updating the submodules...",
+	"Возвращаюсь в настоящее...":     "Returning to the present...",
+	"Завершаю слияние...":            "Finishing the merge...",
+	"Отменяю слияние...":             "Aborting the merge...",
+	"Собираю ветки...":               "Collecting the branches...",
+
+	// Progress of remote operations
+	"Переключаюсь на версию из облака...":                    "Switching to the cloud version...",
+	"Оставляю локальные изменения поверх удалённых...":       "Keeping local changes over the remote ones...",
+	"Отправляю принудительно...":                             "Force pushing...",
+	"Отправляю принудительно":                                "Force pushing",
+	"Забираю версию из облака...":                            "Fetching the cloud version...",
+	"Забираю версию":                                         "Fetching the version",
+	"Отправляю заметки":                                      "Pushing the notes",
+	"Проверяю связь с облаком...":                            "Checking the connection to the cloud...",
+	"Проверяю связь":                                         "Checking the connection",
+	"%s: сеть моргнула, попытка %d из %d...":                 "%s: the network blinked, attempt %d of %d...",
+	"Забираю изменения из облака...":                         "Fetching changes from the cloud...",
+	"Перекладываю свои сейвы поверх облачных...":             "Replaying your saves on top of the cloud ones...",
+	"Забираю изменения":                                      "Fetching changes",
+	"Отправляю сейвы в облако...":                            "Sending the saves to the cloud...",
+	"Отправляю сейвы":                                        "Sending the saves",
+	"Обычная отправка не прошла, отправляю принудительно...": "A normal push didn't go through, force pushing...",
+	"Докачиваю историю":                                      "Fetching the history",
+
+	// Operation results
+	"Машина времени не запущена в этой папке": "The time machine isn't running in this folder",
+	"Момент зафиксирован: %.7s":               "Moment saved: %.7s",
+	"Не удалось перемотать: %v":               "Failed to rewind: %v",
+	"Успешно перемотали к моменту: %.7s":      "Rewound to the moment: %.7s",
+	"не удалось запустить машину времени: %w": "failed to start the time machine: %w",
+	"Схлопнуто сейвов: %d → %.7s":             "Saves squashed: %d → %.7s",
+	"Добавлено в сейв файлов: %d":             "Files added to the save: %d",
+	"Убрано из сейва файлов: %d":              "Files taken out of the save: %d",
+	"Убрано из сейва: %s":                     "Taken out of the save: %s",
+	" ⚠️ Ошибка: ":                            " ⚠️ Error: ",
+	" ✓ Копия создана":                        " ✓ Copy created",
+
+	// Command line
+	"Неизвестная команда: %s\n":                                                            "Unknown command: %s\n",
+	"описание сейва (по умолчанию checkpoint.defaultMessage)":                              "save description (checkpoint.defaultMessage by default)",
+	"имя автора этого сейва (или $":                                                        "author name for this save (or $",
+	"сохранить только подготовленное (git add), без остальных изменений":                   "save only what's staged (git add), without the other changes",
+	"включать новые файлы (--untracked=false — только изменения уже известных git файлов)": "include new files (--untracked=false saves only changes to files git already tracks)",
+	"email автора этого сейва (или $":                                                      "author email for this save (or $",
+	"не спрашивать подтверждения, даже если файлов больше checkpoint.confirmFiles":         "don't ask for confirmation, even with more files than checkpoint.confirmFiles",
+	"дата сейва вместо текущей: 2006-01-02, \"2006-01-02 15:04\" или RFC 3339":             "save date instead of now: 2006-01-02, \"2006-01-02 15:04\" or RFC 3339",
+	"Ошибка: %v\n": "Error: %v\n",
+	"Ошибка: неожиданный ответ %T\n":        "Error: unexpected response %T\n",
+	"вывести статус в JSON":                 "print the status as JSON",
+	"работать в этой папке, а не в текущей": "work in this folder instead of the current one",
+
+	// Settings
+	"профиль %q не найден": "profile %q not found",
+	"профиль %q: %w":       "profile %q: %w",
+
+	// Units and plural forms, English uses the first and last form
+	"только что":    "just now",
+	"%d %s назад":   "%d %s ago",
+	"вчера":         "yesterday",
+	"минуту":        "minute",
+	"минут":         "minutes",
+	"час":           "hour",
+	"часов":         "hours",
+	"день":          "day",
+	"дней":          "days",
+	"месяц":         "month",
+	"месяцев":       "months",
+	"год":           "year",
+	"лет":           "years",
+	"%d Б":          "%d B",
+	"КБ":            "KB",
+	"МБ":            "MB",
+	"ГБ":            "GB",
+	"ТБ":            "TB",
+	"несохранённый": "unpushed",
+	"несохранённых": "unpushed",
+	"момент":        "checkpoint",
+	"моментов":      "checkpoints",
+	"чужой":         "remote",
+	"чужих":         "remote",
+	"сейв":          "save",
+	"сейвов":        "saves",
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"os"
+	"strings"
+)
+
+// Supported UI languages
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+)
+
+// Language is the UI language, picked once at startup from VIBEGIT_LANG or,
+// when that isn't set, LANG. Anything but English keeps the Russian texts.
+var Language = detectLanguage()
+
+// detectLanguage reads the language from the environment
+func detectLanguage() string {
+	for _, name := range []string{"VIBEGIT_LANG", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(value), LanguageEnglish) {
+			return LanguageEnglish
+		}
+		return LanguageRussian
+	}
+	return LanguageRussian
+}
+
+// T returns text in the UI language. The Russian text is the message id: it
+// is looked up in the table of the language and returned unchanged when the
+// table has no translation for it.
+func T(text string) string {
+	if Language == LanguageEnglish {
+		if translated, ok := english[text]; ok {
+			return translated
+		}
+	}
+	return text
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
@@ -8,35 +11,604 @@ import (
 
 // Model represents the application state
 type Model struct {
-	Repo              *git.Repository
-	Status            *GitStatus
-	Err               error
-	Selected          int
-	Quitting          bool
-	Checkpoints       []Checkpoint
-	HistoryMode       bool
-	HistorySelected   int
+	Repo                 *git.Repository
+	Status               *GitStatus
+	Err                  error
+	Selected             int
+	Quitting             bool
+	Checkpoints          []Checkpoint
+	HistoryMode          bool
+	HistorySelected      int
+	HistoryDetail        bool
+	HistoryShallow       bool
+	HistoryRelativeDates bool
+	// Lines added and deleted by each checkpoint, filled in as the history
+	// is scrolled
+	HistoryStats        map[string]DiffStat
+	HistoryStatsLoading bool
+	// HasMore is set while older checkpoints are left to load, they come a
+	// page at a time as the history is scrolled down
+	HasMore            bool
+	HistoryLoadingMore bool
+	// HistoryBranch names the other branch whose history is browsed, such
+	// a history is read-only
+	HistoryBranch string
+	// HistoryCompact collapses runs of tool checkpoints into groups, the
+	// ones in HistoryExpanded (by their newest hash) are shown in full
+	HistoryCompact  bool
+	HistoryExpanded map[string]bool
+	// HistoryCompareBase is the checkpoint marked to compare another one
+	// with, empty when none is
+	HistoryCompareBase string
+	// HistoryOffset is the first history row on screen
+	HistoryOffset int
+	// HistoryFilter narrows the history to the matching checkpoints while
+	// Checkpoints keeps them all, HistoryFiltering is set while it's typed
+	HistoryFilter    string
+	HistoryFiltering bool
+	// WholeRepo shows the status of the whole repository when started in
+	// a subdirectory, instead of only what changed under it
+	WholeRepo         bool
 	Loading           bool
 	LoadingText       string
 	SyncMessage       string
 	ShowSyncMessage   bool
 	GitNotInitialized bool
+	WorkDirGone       bool
 	// Description input mode
 	DescriptionMode  bool
 	DescriptionInput string
 	Suggestions      []string
+	EstimatedSize    int64
+	// Navigable file list mode
+	FilesMode     bool
+	FilesSelected int
+	// Pre-checkpoint staging screen
+	StagingMode   bool
+	StagingCursor int
+	SelectedFiles []string
+	// Checkpoint only what is already staged
+	StagedOnly bool
+	// Marker checkpoint that is allowed to have no changes
+	MarkerMode bool
+	// Description input names a squash of tool checkpoints
+	SquashMode  bool
+	SquashCount int
+	// Run sync right after the checkpoint is created
+	SyncAfterCheckpoint bool
+	CheckpointNotice    string
+	// Scrollable diff viewer
+	DiffMode   bool
+	DiffTitle  string
+	DiffPatch  string
+	DiffOffset int
+	// DiffWorkingTree is set while showing unsaved changes
+	DiffWorkingTree bool
+	// Terminal size from the last WindowSizeMsg
+	Width  int
+	Height int
+	// Always show the one-line status
+	CompactStatus bool
+	// Draw ASCII markers instead of emoji
+	PlainText bool
+	// Moving past the end of the menu or history jumps to the other end
+	WrapNavigation bool
+	// RelativeDatesLimit is the age up to which the history shows relative
+	// dates while HistoryRelativeDates is on, zero for any age
+	RelativeDatesLimit time.Duration
+	// Asking whether to save a checkpoint touching ConfirmFiles files, more
+	// than ConfirmLimit
+	ConfirmFiles int
+	ConfirmLimit int
+	// Result of the last background operation
+	Notice  string
+	Warning string
+	// Choosing how to resolve a rejected pull
+	ConflictMode     bool
+	ConflictSelected int
+	ConflictReason   string
+	// ConflictOverwrites is what keeping the local version discards
+	ConflictOverwrites *OperationSummary
+	// ConflictCanForce offers keeping the local version, which force pushes
+	ConflictCanForce bool
+	// Recap of the last destructive operation, dismissed by any key
+	Summary *OperationSummary
+	// Outcome of the last successful sync, cleared by any key
+	SyncResult *SyncMsg
+	// What the rollback being confirmed would change
+	RollbackPreview *RollbackPreview
+	// Branch picker, BranchInput holds the name of a new branch while
+	// BranchCreating
+	BranchMode     bool
+	Branches       []BranchEntry
+	BranchSelected int
+	BranchCreating bool
+	BranchInput    string
+	// Stash list
+	StashMode     bool
+	Stashes       []StashEntry
+	StashSelected int
+	// Journal of the operations performed by the tool
+	ActivityMode     bool
+	Activity         []ActivityEntry
+	ActivitySelected int
+	// RecentActivity is what was recorded since the last key press, newest
+	// first, shown under the loading text of multi-step operations
+	RecentActivity []ActivityEntry
+	// Switching between config profiles, "" stands for no profile
+	ProfileMode     bool
+	ProfileSelected int
+	Profiles        []string
+	Profile         string
+	// Jumping to a commit by hash, branch, tag or revision expression
+	RefMode     bool
+	RefInput    string
+	RefTarget   *Checkpoint
+	RefSelected int
+	// Searching checkpoints by text to roll back to one
+	SearchMode     bool
+	SearchInput    string
+	SearchSelected int
+	// New files are preselected in the checkpoint checklist
+	IncludeUntracked bool
+	// Hide the one-line explanation under the selected menu item
+	HideMenuHelp bool
+	// Naming a new branch that starts at the checkpoint BranchFromHash
+	BranchFromMode  bool
+	BranchFromHash  string
+	BranchFromInput string
+	// Naming a tag for the checkpoint TagHash
+	TagMode  bool
+	TagHash  string
+	TagInput string
+	// Editing the note of the checkpoint NoteHash
+	NoteMode  bool
+	NoteHash  string
+	NoteInput string
+	// Asking for the file to blame at the checkpoint BlameHash
+	BlameMode  bool
+	BlameHash  string
+	BlameInput string
+	// Switching to a recently opened repository
+	RecentMode     bool
+	Recent         []string
+	RecentSelected int
+	// Setting the git identity: the name is asked first, then the email
+	IdentityMode      bool
+	IdentityName      string
+	IdentityInput     string
+	IdentityDismissed bool
+}
+
+// CompactStatusHeight is the terminal height below which the status
+// collapses to a single line
+const CompactStatusHeight = 24
+
+// UseCompactStatus reports whether the status should take a single line
+func (m *Model) UseCompactStatus() bool {
+	return m.CompactStatus || (m.Height > 0 && m.Height < CompactStatusHeight)
+}
+
+// DiffFile returns the file whose part of the diff is at the top of the
+// screen, empty when the diff has no file headers
+func (m *Model) DiffFile() string {
+	file := ""
+	for i, line := range strings.Split(m.DiffPatch, "\n") {
+		if i > m.DiffOffset && file != "" {
+			break
+		}
+		if !strings.HasPrefix(line, "diff --git ") {
+			continue
+		}
+		if at := strings.LastIndex(line, " b/"); at >= 0 {
+			file = line[at+len(" b/"):]
+		}
+	}
+	return file
+}
+
+// DiffPageSize returns how many diff lines fit on the screen
+func (m *Model) DiffPageSize() int {
+	if m.Height == 0 {
+		return 20
+	}
+	// Title, diff header, position line and help take the rest
+	return max(m.Height-8, 3)
+}
+
+// OnMainScreen reports whether the status and menu are shown, with no
+// screen, prompt or operation on top of them
+func (m *Model) OnMainScreen() bool {
+	return !m.Loading && m.Summary == nil && m.RollbackPreview == nil && m.ConfirmFiles == 0 &&
+		!m.ConflictMode && !m.ProfileMode && !m.ActivityMode && !m.StashMode && !m.BranchMode &&
+		m.RefTarget == nil && !m.RefMode && !m.SearchMode && !m.IdentityMode && !m.RecentMode &&
+		!m.DiffMode && !m.DescriptionMode && !m.HistoryMode && !m.StagingMode && !m.FilesMode
+}
+
+// ShowIdentityBanner reports whether to warn that git has no user identity
+func (m *Model) ShowIdentityBanner() bool {
+	return m.Status != nil && m.Status.MissingIdentity && !m.IdentityDismissed
+}
+
+// SearchResults returns the loaded checkpoints matching the search input
+func (m *Model) SearchResults() []Checkpoint {
+	var results []Checkpoint
+	for _, checkpoint := range m.Checkpoints {
+		if MatchCheckpoint(checkpoint, m.SearchInput) {
+			results = append(results, checkpoint)
+		}
+	}
+	return results
+}
+
+// HistoryRow is a line of the history: a single checkpoint, or a collapsed
+// group of Count checkpoints starting at Index
+type HistoryRow struct {
+	Index int
+	Count int
+}
+
+// checkpointGroup returns the bounds of the run of two or more consecutive
+// tool checkpoints holding index i, ok is false outside such a run
+func (m *Model) checkpointGroup(i int) (start, end int, ok bool) {
+	isTool := func(j int) bool {
+		return m.Checkpoints[j].Tool
+	}
+	if i < 0 || i >= len(m.Checkpoints) || !isTool(i) {
+		return 0, 0, false
+	}
+	start, end = i, i+1
+	for start > 0 && isTool(start-1) {
+		start--
+	}
+	for end < len(m.Checkpoints) && isTool(end) {
+		end++
+	}
+	return start, end, end-start > 1
+}
+
+// HistoryRows returns the lines of the history. In compact mode runs of
+// tool checkpoints that weren't expanded collapse into one row, manual
+// commits always get their own. A filter lists just the matching
+// checkpoints, each on its own row.
+func (m *Model) HistoryRows() []HistoryRow {
+	rows := make([]HistoryRow, 0, len(m.Checkpoints))
+	if m.HistoryFilter != "" {
+		for i, checkpoint := range m.Checkpoints {
+			if MatchCheckpoint(checkpoint, m.HistoryFilter) {
+				rows = append(rows, HistoryRow{Index: i, Count: 1})
+			}
+		}
+		return rows
+	}
+	for i := 0; i < len(m.Checkpoints); {
+		start, end, ok := m.checkpointGroup(i)
+		if m.HistoryCompact && ok && !m.HistoryExpanded[m.Checkpoints[start].Hash] {
+			rows = append(rows, HistoryRow{Index: start, Count: end - start})
+			i = end
+			continue
+		}
+		rows = append(rows, HistoryRow{Index: i, Count: 1})
+		i++
+	}
+	return rows
+}
+
+// HistoryPageSize returns how many history rows fit on the screen
+func (m *Model) HistoryPageSize() int {
+	if m.Height == 0 {
+		return 20
+	}
+	// Title, summary, scroll markers and help take the rest
+	return max(m.Height-10, 3)
+}
+
+// HistoryWindow returns the rows shown on screen: from HistoryOffset,
+// moved just enough to keep the selection in view
+func (m *Model) HistoryWindow(rows []HistoryRow) (start, end int) {
+	page := m.HistoryPageSize()
+	selected := HistoryRowOf(rows, m.HistorySelected)
+	start = max(min(m.HistoryOffset, len(rows)-page), 0)
+	if selected < start {
+		start = selected
+	}
+	if selected >= start+page {
+		start = selected - page + 1
+	}
+	return start, min(start+page, len(rows))
+}
+
+// KeepHistorySelectionVisible scrolls the history to the selection
+func (m *Model) KeepHistorySelectionVisible() {
+	m.HistoryOffset, _ = m.HistoryWindow(m.HistoryRows())
+}
+
+// HistoryRowOf returns the position in rows of the row holding the
+// checkpoint at index
+func HistoryRowOf(rows []HistoryRow, index int) int {
+	for i, row := range rows {
+		if index >= row.Index && index < row.Index+row.Count {
+			return i
+		}
+	}
+	return 0
+}
+
+// SetHistoryFilter narrows the history to query and moves the selection
+// onto a matching checkpoint when it's no longer listed
+func (m *Model) SetHistoryFilter(query string) {
+	m.HistoryFilter = query
+	rows := m.HistoryRows()
+	if len(rows) > 0 && !m.HistorySelectionListed() {
+		m.HistorySelected = rows[0].Index
+	}
+}
+
+// NeedsMoreHistory reports whether the next page of checkpoints should be
+// loaded: older ones are left and the selection is within a screen of the
+// end of the list, or a filter found nothing among the loaded ones
+func (m *Model) NeedsMoreHistory() bool {
+	if !m.HasMore || m.HistoryLoadingMore {
+		return false
+	}
+	rows := m.HistoryRows()
+	if !m.HistorySelectionListed() {
+		return true
+	}
+	return HistoryRowOf(rows, m.HistorySelected) >= len(rows)-m.HistoryPageSize()
+}
+
+// HistorySelectionListed reports whether the selected checkpoint is on one
+// of the history rows, a filter matching nothing leaves it hidden
+func (m *Model) HistorySelectionListed() bool {
+	for _, row := range m.HistoryRows() {
+		if m.HistorySelected >= row.Index && m.HistorySelected < row.Index+row.Count {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleHistoryGroup expands the collapsed group holding the selected
+// checkpoint, or collapses the expanded one back. It reports whether the
+// selection is in a group at all.
+func (m *Model) ToggleHistoryGroup() bool {
+	start, _, ok := m.checkpointGroup(m.HistorySelected)
+	if !ok {
+		return false
+	}
+	hash := m.Checkpoints[start].Hash
+	if m.HistoryExpanded == nil {
+		m.HistoryExpanded = make(map[string]bool)
+	}
+	if m.HistoryExpanded[hash] {
+		delete(m.HistoryExpanded, hash)
+		// The selection lands on the collapsed row
+		m.HistorySelected = start
+	} else {
+		m.HistoryExpanded[hash] = true
+	}
+	return true
+}
+
+// MatchCheckpoint reports whether the checkpoint's message, author, tags or
+// hash contain every word of the query, ignoring case
+func MatchCheckpoint(checkpoint Checkpoint, query string) bool {
+	haystack := strings.ToLower(strings.Join(append([]string{
+		checkpoint.Message, checkpoint.Author, checkpoint.Hash,
+	}, checkpoint.Tags...), " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
 }
 
 // GitStatus represents git repository status
+// The JSON form is printed by `status --json` for editor integrations,
+// keep the field names stable.
 type GitStatus struct {
-	Branch     string
-	Staged     []string
-	Modified   []string
-	Untracked  []string
-	Ahead      int
-	Behind     int
-	IsClean    bool
-	LastCommit string
+	// Root is the top directory of the repository
+	Root      string   `json:"root"`
+	Branch    string   `json:"branch"`
+	Staged    []string `json:"staged"`
+	Modified  []string `json:"modified"`
+	Untracked []string `json:"untracked"`
+	Deleted   []string `json:"deleted"`
+	// Renamed pairs deleted files with new ones of the same content. Both
+	// paths stay in the lists above, the status shows them as one move.
+	Renamed []Rename `json:"renamed"`
+	Ahead   int      `json:"ahead"`
+	Behind  int      `json:"behind"`
+	// Unpushed counts the checkpoints made by the tool that the remote
+	// branch doesn't have yet
+	Unpushed          int             `json:"unpushed"`
+	IsClean           bool            `json:"isClean"`
+	LastCommitMessage string          `json:"lastCommitMessage"`
+	LastCommitHash    string          `json:"lastCommitHash"`
+	LastCommitDate    time.Time       `json:"lastCommitDate"`
+	LastCommitAuthor  string          `json:"lastCommitAuthor"`
+	Submodules        []SubmoduleInfo `json:"submodules"`
+	Noise             []string        `json:"noise"`
+	// Large are changed files too big to read, compared by size and date
+	Large []string `json:"large"`
+	// MissingIdentity is set when git has no user.name or user.email
+	MissingIdentity bool `json:"missingIdentity"`
+	// MergeInProgress is set while a merge waits to be committed or aborted
+	MergeInProgress bool `json:"mergeInProgress"`
+	// Present is the tip the branch had before rolling back, empty unless
+	// HEAD is still in its past
+	Present string `json:"present,omitempty"`
+	// Scope is the directory the app was started in relative to Root,
+	// slash separated, empty at the top of the repository
+	Scope string `json:"scope,omitempty"`
+}
+
+// InScope reports whether path lies under the launch directory
+func (s *GitStatus) InScope(path string) bool {
+	return s.Scope == "" || strings.HasPrefix(path, s.Scope+"/")
+}
+
+// Scoped returns a copy listing only the changed files under the launch
+// directory, along with how many were left out
+func (s *GitStatus) Scoped() (*GitStatus, int) {
+	scoped := *s
+	hidden := make(map[string]bool)
+	filter := func(paths []string) []string {
+		var kept []string
+		for _, path := range paths {
+			if s.InScope(path) {
+				kept = append(kept, path)
+			} else {
+				hidden[path] = true
+			}
+		}
+		return kept
+	}
+	scoped.Staged = filter(s.Staged)
+	scoped.Modified = filter(s.Modified)
+	scoped.Untracked = filter(s.Untracked)
+	scoped.Deleted = filter(s.Deleted)
+	scoped.Renamed = nil
+	for _, rename := range s.Renamed {
+		if s.InScope(rename.From) || s.InScope(rename.To) {
+			scoped.Renamed = append(scoped.Renamed, rename)
+		}
+	}
+	return &scoped, len(hidden)
+}
+
+// Rename is a file moved to another path without changing its content
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IsRenamed reports whether path is either end of a rename
+func (s *GitStatus) IsRenamed(path string) bool {
+	for _, rename := range s.Renamed {
+		if rename.From == path || rename.To == path {
+			return true
+		}
+	}
+	return false
+}
+
+// FileCategory describes which status section a file belongs to
+type FileCategory int
+
+const (
+	FileStaged FileCategory = iota
+	FileModified
+	FileUntracked
+	FileDeleted
+)
+
+// StatusFile is a single entry of the navigable file list
+type StatusFile struct {
+	Path     string
+	Category FileCategory
+}
+
+// Files returns all changed files in display order
+func (s *GitStatus) Files() []StatusFile {
+	var files []StatusFile
+	for _, path := range s.Staged {
+		files = append(files, StatusFile{Path: path, Category: FileStaged})
+	}
+	for _, path := range s.Modified {
+		files = append(files, StatusFile{Path: path, Category: FileModified})
+	}
+	for _, path := range s.Untracked {
+		files = append(files, StatusFile{Path: path, Category: FileUntracked})
+	}
+	for _, path := range s.Deleted {
+		files = append(files, StatusFile{Path: path, Category: FileDeleted})
+	}
+	return files
+}
+
+// ChangedFiles returns every changed path once, sorted by path.
+// A file that is both staged and changed again is reported by its worktree state.
+func (s *GitStatus) ChangedFiles() []StatusFile {
+	categories := make(map[string]FileCategory)
+	for _, path := range s.Staged {
+		categories[path] = FileStaged
+	}
+	for _, path := range s.Modified {
+		categories[path] = FileModified
+	}
+	for _, path := range s.Untracked {
+		categories[path] = FileUntracked
+	}
+	for _, path := range s.Deleted {
+		categories[path] = FileDeleted
+	}
+
+	files := make([]StatusFile, 0, len(categories))
+	for path, category := range categories {
+		files = append(files, StatusFile{Path: path, Category: category})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// IsFileSelected reports whether the path is selected for the next checkpoint
+func (m *Model) IsFileSelected(path string) bool {
+	for _, selected := range m.SelectedFiles {
+		if selected == path {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleFile selects or deselects the path for the next checkpoint
+func (m *Model) ToggleFile(path string) {
+	for i, selected := range m.SelectedFiles {
+		if selected == path {
+			m.SelectedFiles = append(m.SelectedFiles[:i], m.SelectedFiles[i+1:]...)
+			return
+		}
+	}
+	m.SelectedFiles = append(m.SelectedFiles, path)
+}
+
+// IsNoise reports whether the changed path is configured as noise
+func (s *GitStatus) IsNoise(path string) bool {
+	for _, noise := range s.Noise {
+		if noise == path {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStagedOnly reports whether everything changed in path is already in
+// the next checkpoint
+func (s *GitStatus) IsStagedOnly(path string) bool {
+	return slices.Contains(s.Staged, path) && !slices.Contains(s.Modified, path) &&
+		!slices.Contains(s.Untracked, path) && !slices.Contains(s.Deleted, path)
+}
+
+// IsLarge reports whether the changed path was compared without reading it
+func (s *GitStatus) IsLarge(path string) bool {
+	for _, large := range s.Large {
+		if large == path {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmoduleInfo represents a submodule and its checkout state
+type SubmoduleInfo struct {
+	Path        string `json:"path"`
+	Initialized bool   `json:"initialized"`
+	Clean       bool   `json:"clean"`
 }
 
 // Checkpoint represents a git commit checkpoint
@@ -46,12 +618,117 @@ type Checkpoint struct {
 	Author    string
 	Date      time.Time
 	IsCurrent bool
+	Tags      []string
+	// Tool is set for checkpoints made by VibeGit rather than by hand
+	Tool bool
+	// Pinned checkpoints are never squashed
+	Pinned bool
+	// Note is the git note attached afterwards, empty when there is none
+	Note string
+}
+
+// HookResult is the outcome of one hook command
+type HookResult struct {
+	Command string
+	Output  string
+	// Err describes why the command failed, empty when it succeeded
+	Err string
+}
+
+// DiffStat counts the lines a checkpoint added and deleted
+type DiffStat struct {
+	Added   int
+	Deleted int
+}
+
+// BranchEntry is one local branch
+type BranchEntry struct {
+	Name    string
+	Hash    string
+	Date    time.Time
+	Current bool
+}
+
+// StashEntry is one entry of the git stash
+type StashEntry struct {
+	Index   int
+	Hash    string
+	Message string
+	Date    time.Time
+}
+
+// ActivityEntry is one operation in the activity log
+type ActivityEntry struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Action  string    `json:"action"`
+	Success bool      `json:"success"`
+	Outcome string    `json:"outcome"`
+}
+
+// RecentActivityLimit caps how many recent entries the loading screen keeps
+const RecentActivityLimit = 5
+
+// Actions recorded in the activity log
+const (
+	ActivityCheckpoint = "Сейв"
+	ActivityRollback   = "Откат"
+	ActivitySync       = "Синк"
+	ActivityForcePush  = "Принудительный синк"
+	ActivityConflict   = "Конфликт"
+	ActivitySquash     = "Схлопывание"
+	ActivityMerge      = "Слияние"
+	ActivityBranch     = "Ветка"
+	ActivityUndo       = "Отмена сейва"
+	ActivityRevert     = "Откат с историей"
+)
+
+// OperationSummary recaps what a destructive operation changed
+type OperationSummary struct {
+	Title   string
+	OldHead string
+	NewHead string
+	// Affected counts the commits that are no longer reachable, Commits
+	// lists the first few of them
+	Affected int
+	Commits  []string
+}
+
+// PreviewChange is how a rollback would change a file
+type PreviewChange int
+
+const (
+	PreviewModified PreviewChange = iota
+	PreviewCreated
+	PreviewDeleted
+)
+
+// PreviewFile is a file a rollback would change
+type PreviewFile struct {
+	Path   string
+	Change PreviewChange
+}
+
+// RollbackPreview describes what a rollback would do before it runs
+type RollbackPreview struct {
+	Hash    string
+	Message string
+	Files   []PreviewFile
+	// Commits counts the saves that would no longer be in the branch history,
+	// Unpushed how many of them the remote doesn't have either
+	Commits  int
+	Unpushed int
+	// Unsaved counts the files with uncommitted changes, AutoSaved tells
+	// whether they get checkpointed before the reset or are lost
+	Unsaved   int
+	AutoSaved bool
 }
 
 // Message types for Bubble Tea
 type (
 	StatusMsg struct {
-		Text string
+		Text    string
+		Summary *OperationSummary
 	}
 
 	CheckpointCreatedMsg struct {
@@ -61,11 +738,74 @@ type (
 
 	CheckpointsLoadedMsg struct {
 		Checkpoints []Checkpoint
+		// Shallow is set when older history wasn't downloaded
+		Shallow bool
+		// Canceled is set when the load was interrupted, Checkpoints then
+		// holds the newest ones loaded until that moment
+		Canceled bool
+		// Branch names the branch whose history was loaded when it isn't
+		// the checked out one
+		Branch string
+		// Empty is set when the repository has no commits yet
+		Empty bool
+		// HasMore is set when only the newest checkpoints were loaded and
+		// older ones are left for LoadMoreCheckpoints
+		HasMore bool
+	}
+
+	// MoreCheckpointsLoadedMsg carries the next page of the history, the
+	// checkpoints older than After
+	MoreCheckpointsLoadedMsg struct {
+		After       string
+		Checkpoints []Checkpoint
+		HasMore     bool
+		Shallow     bool
+	}
+
+	// HooksMsg reports the hooks run for a lifecycle event
+	HooksMsg struct {
+		Event      string
+		Results    []HookResult
+		ShowOutput bool
+	}
+
+	// ConfirmCheckpointMsg asks whether a checkpoint touching more files
+	// than the configured limit is meant
+	ConfirmCheckpointMsg struct {
+		Files int
+		Limit int
+	}
+
+	// CheckpointStatsMsg carries the diff stats of some checkpoints by hash
+	CheckpointStatsMsg struct {
+		Stats map[string]DiffStat
+	}
+
+	// NoteMsg carries the note of a checkpoint after reading or saving it
+	NoteMsg struct {
+		Hash string
+		Note string
+	}
+
+	// PinnedMsg reports a checkpoint was pinned or unpinned
+	PinnedMsg struct {
+		Hash   string
+		Pinned bool
+	}
+
+	// TaggedMsg reports a tag was put on a checkpoint
+	TaggedMsg struct {
+		Hash string
+		Tag  string
 	}
 
 	RollbackMsg struct {
 		Success bool
 		Message string
+		Summary *OperationSummary
+		// Backup is the branch keeping the changes saved before the
+		// rollback, empty when there were none
+		Backup string
 	}
 
 	SyncMsg struct {
@@ -74,10 +814,20 @@ type (
 		Pulled   bool
 		Pushed   bool
 		Conflict bool
+		// Received and Sent count the commits that came down and went up
+		Received int
+		Sent     int
+		Forced   bool
+		// Summary is set when the remote was overwritten by a force push
+		Summary *OperationSummary
+		// Backup is the branch keeping the unsaved changes made before
+		// taking the cloud version
+		Backup string
 	}
 
 	DescriptionModeMsg struct {
-		Suggestions []string
+		Suggestions   []string
+		EstimatedSize int64
 	}
 
 	GitNotInitializedMsg struct {
@@ -85,8 +835,160 @@ type (
 	}
 
 	GitInitializedMsg struct{}
+
+	// SquashCandidatesMsg lists the subjects of the squashable tool checkpoints
+	SquashCandidatesMsg struct {
+		Messages []string
+	}
+
+	// ProgressMsg updates the loading text while an operation runs
+	ProgressMsg struct {
+		Text string
+	}
+
+	DiffMsg struct {
+		Title string
+		Patch string
+		// WorkingTree is set for the diff of unsaved changes, whose files
+		// can be added to or removed from the next checkpoint in place
+		WorkingTree bool
+	}
+
+	// BranchesLoadedMsg carries the local branches sorted by name
+	BranchesLoadedMsg struct {
+		Branches []BranchEntry
+	}
+
+	// StashesLoadedMsg carries the stash entries, stash@{0} first
+	StashesLoadedMsg struct {
+		Entries []StashEntry
+	}
+
+	// ActivityLoadedMsg carries the latest activity log entries, newest first
+	ActivityLoadedMsg struct {
+		Entries []ActivityEntry
+	}
+
+	// ActivityEntryMsg carries an entry recorded while the app runs
+	ActivityEntryMsg struct {
+		Entry ActivityEntry
+	}
+
+	// RepositoryOpenedMsg reports that the repository to switch to opens
+	RepositoryOpenedMsg struct {
+		Path string
+	}
+
+	// RepositorySwitchedMsg reports that the service now works in another
+	// repository
+	RepositorySwitchedMsg struct {
+		Path      string
+		Profile   string
+		ConfigErr error
+	}
+
+	// AutoCheckpointTickMsg is time for an auto-save
+	AutoCheckpointTickMsg struct{}
+
+	// RollbackPreviewMsg carries what a rollback would change
+	RollbackPreviewMsg struct {
+		Preview RollbackPreview
+	}
+
+	// CheckpointResolvedMsg carries the commit a typed ref points to
+	CheckpointResolvedMsg struct {
+		Checkpoint Checkpoint
+	}
+
+	// RemoteRefreshedMsg reports that a background fetch moved the
+	// remote-tracking refs, the status has to be counted again
+	RemoteRefreshedMsg struct{}
+
+	// ShellExitedMsg reports that the drop-to-shell session ended
+	ShellExitedMsg struct {
+		Err error
+	}
+
+	// ConflictChoiceMsg asks the user how to resolve a rejected pull
+	ConflictChoiceMsg struct {
+		Reason string
+		// Overwrites recaps the remote commits keeping the local version
+		// would discard
+		Overwrites *OperationSummary
+		// CanForce is set when the branch may be force pushed, otherwise
+		// keeping the local version isn't offered
+		CanForce bool
+	}
+)
+
+// RefAction is what to do with the commit found by a typed ref
+type RefAction int
+
+const (
+	// RefRollback resets to the commit
+	RefRollback RefAction = iota
+	// RefDiff shows what the commit changed
+	RefDiff
+	// RefHistory browses the history of the typed branch without checking
+	// it out
+	RefHistory
 )
 
+// RefActions lists the options in the order they are shown
+var RefActions = []struct {
+	Action RefAction
+	Label  string
+}{
+	{RefRollback, "Вернуть этот вайб"},
+	{RefDiff, "Посмотреть, что поменялось в этом сейве"},
+	{RefHistory, "Полистать историю этой ветки, не переключаясь"},
+}
+
+// ConflictChoice is the user's answer to a pull conflict
+type ConflictChoice int
+
+const (
+	// ConflictKeepMine keeps local work and overwrites the remote
+	ConflictKeepMine ConflictChoice = iota
+	// ConflictTakeTheirs resets to the remote branch
+	ConflictTakeTheirs
+	// ConflictManual leaves everything as is and lists the clashing files
+	ConflictManual
+)
+
+// ConflictChoices lists the options in the order they are shown
+var ConflictChoices = []struct {
+	Choice ConflictChoice
+	Label  string
+}{
+	{ConflictKeepMine, "Оставить мои изменения (облако перезапишется)"},
+	{ConflictTakeTheirs, "Взять версию из облака (мои незапушенные сейвы пропадут)"},
+	{ConflictManual, "Отмена, разберусь сам"},
+}
+
+// ConflictOptions returns the conflict choices that can be picked. Keeping
+// the local version force pushes, so it is left out when that isn't allowed.
+func (m Model) ConflictOptions() []ConflictChoice {
+	options := make([]ConflictChoice, 0, len(ConflictChoices))
+	for _, option := range ConflictChoices {
+		if option.Choice == ConflictKeepMine && !m.ConflictCanForce {
+			continue
+		}
+		options = append(options, option.Choice)
+	}
+	return options
+}
+
+// ConflictLabel returns the label of choice
+func ConflictLabel(choice ConflictChoice) string {
+	for _, option := range ConflictChoices {
+		if option.Choice == choice {
+			return option.Label
+		}
+	}
+	return ""
+}
+
 // ErrMsg wraps an error for Bubble Tea
 type ErrMsg struct {
 	Error error
@@ -99,59 +1001,372 @@ const (
 	MenuViewHistory      = "История потока (Flow History)"
 	MenuRollback         = "Вернуть прошлый вайб"
 	MenuSync             = "Синкнуть с облаком"
+	MenuSaveAndSync      = "Сейв + Синк"
+	MenuViewChanges      = "Посмотреть изменения"
+	MenuCreateMarker     = "Поставить метку в истории"
+	MenuSquash           = "Схлопнуть сейвы перед пушем"
+	MenuUpdateSubmodules = "Подтянуть субмодули"
+	MenuMergeContinue    = "Завершить слияние"
+	MenuMergeAbort       = "Отменить слияние"
+	MenuReturnToPresent  = "Вернуться в настоящее"
+	MenuBranches         = "Ветки"
 )
 
+// MenuDescriptions explain the menu items to newcomers, one line each
+var MenuDescriptions = map[string]string{
+	MenuInitGit:          "Создаст git-репозиторий в этой папке, чтобы было куда сейвить",
+	MenuCreateCheckpoint: "Запомнит текущее состояние файлов, к нему всегда можно вернуться",
+	MenuViewHistory:      "Покажет все сейвы: что, когда и кто, с диффом каждого",
+	MenuRollback:         "Вернёт файлы к одному из прошлых сейвов, сначала покажет, что изменится",
+	MenuSync:             "Отправит твои сейвы в облако и заберёт чужие",
+	MenuSaveAndSync:      "Засейвит и сразу синканёт, одним заходом",
+	MenuViewChanges:      "Покажет, что изменилось с последнего сейва",
+	MenuCreateMarker:     "Пустой сейв-закладка, например «начало рефакторинга»",
+	MenuSquash:           "Склеит автоматические сейвы в один, чтобы история в облаке была чище",
+	MenuUpdateSubmodules: "Скачает версии субмодулей, записанные в проекте",
+	MenuMergeContinue:    "Сохранит слияние с тем, как ты разрешил конфликты в файлах",
+	MenuMergeAbort:       "Вернёт файлы как до слияния, правки после его начала пропадут",
+	MenuReturnToPresent:  "Отменит откаты: ветка снова будет там, где была до первого из них",
+	MenuBranches:         "Переключит на другую ветку или заведёт новую, чтобы экспериментировать отдельно",
+}
+
 // UI text constants
 const (
-	TitleMain         = " VibeGit Flow 🌊 "
-	TitleDescription  = " VibeGit [Сейвим вайб] "
-	PromptDescription = "Опиши этот момент потока:"
-	PromptSuggestions = "💡 Или выбери муд:"
-	HelpMain          = "↑↓ Навигация | Enter Выбрать | q Выход"
-	HelpHotkeys       = "Хоткеи: [C] Сейв [H] История [R] Ресет [S] Синк"
-	HelpDescription   = "[Enter Засейвить] [Esc Отмена] [1-9 Быстрый выбор]"
-	HelpHistory       = "↑↓ Листать | Enter Вернуть этот вайб | Esc Назад"
-	LabelActions      = "Что делаем:"
-	LabelHistory      = "Твой флоу:"
-	LabelBranch       = "Ветка:"
-	LabelLastCommit   = "Последний сейв:"
-	LabelStaged       = "Готово к сейву:"
-	LabelModified     = "Изменилось:"
-	LabelUntracked    = "Новое:"
-	TextNoCheckpoints = "Вайбов пока нет, начинай творить"
-	TextCurrent       = " (текущий вайб)"
-	TextClean         = "✓ Ты в потоке. Всё чисто."
-	TextDirty         = "⚡ Есть незасейвленный прогресс"
-	TextLoading       = "В процессе: "
+	TitleMain                = " VibeGit Flow 🌊 "
+	TitleRepo                = " VibeGit 🌊 %s · %s "
+	TitleDescription         = " VibeGit [Сейвим вайб] "
+	TitleMarker              = " VibeGit [Метка в истории] "
+	TitleSquash              = " VibeGit [Схлопываем сейвы: %d] "
+	TitleWorkingTreeDiff     = "Незасейвленные изменения"
+	TitleBlame               = "Кто писал %s на момент сейва %.7s"
+	PromptBlame              = "Какой файл показать на момент сейва %.7s? Путь от корня проекта:"
+	HelpBlame                = "[Enter Показать] [Esc Отмена]"
+	PromptNote               = "Заметка к сейву %.7s (пусто — удалить):"
+	HelpNote                 = "[Enter Сохранить] [Esc Отмена]"
+	TitleWorkingTreeSince    = "Что изменилось с сейва %.7s: %s"
+	TitleCheckpointDiff      = "Сейв %.7s: %s"
+	TitleCompareCheckpoints  = "Что изменилось от сейва %.7s до сейва %.7s"
+	PromptDescription        = "Опиши этот момент потока:"
+	PromptSuggestions        = "💡 Или выбери муд:"
+	HelpMain                 = "↑↓ Навигация | Enter Выбрать | ? Подсказки | q Выход"
+	HelpHotkeys              = "Хоткеи: [C] Сейв [H] История [R] Ресет [S] Синк [P] Сейв+Синк [D] Дифф [M] Метка [F] Файлы [G] К сейву [/] Найти и откатиться [U] Отменить сейв [Z] Отложенное [B] Ветки [W] Проекты [L] Журнал [O] Профиль [V] Вид [!] Терминал"
+	HelpDescription          = "[Enter Засейвить] [Esc Отмена] [1-9 Быстрый выбор]"
+	HelpHistory              = "↑↓ Листать | PgUp/PgDn Страница | Enter Вернуть этот вайб | I Подробно | S Что поменялось в сейве | W Что изменилось с тех пор | B Кто писал файл | Shift+B Ветка отсюда | P Закрепить | N Заметка | T Тег | Пробел База сравнения | D Даты или сравнить | C Свернуть сейвы | E Раскрыть группу | V Проверить цепочку | / Фильтр | Esc Назад"
+	TextCheckpointGroup      = "%d %s 🌊 %s — %s"
+	TextHistoryMoreAbove     = "▲ ещё %d"
+	TextHistoryMoreBelow     = "▼ ещё %d"
+	TextHistoryOlder         = "▼ дальше старые сейвы, загрузятся при прокрутке"
+	TextHistoryLoadingOlder  = "▼ загружаю старые сейвы..."
+	HelpStaging              = "↑↓ Листать | Space Выбрать | A Все/никого | N Новые файлы | Enter Дальше | Esc Отмена"
+	TextUntrackedOn          = "Новые файлы включаются в сейв"
+	TextUntrackedOff         = "Новые файлы не включаются в сейв"
+	HelpDiff                 = "↑↓ Листать | PgUp/PgDn Страница | Esc Назад"
+	HelpWorkingTreeDiff      = "↑↓ Листать | PgUp/PgDn Страница | S В сейв | U Из сейва | Esc Назад"
+	LabelDiffFile            = "Файл: %s"
+	TextDiffFileStaged       = " ✓ в сейве"
+	HelpFiles                = "↑↓ Листать | U Убрать из сейва | Shift+U Убрать всё | R Вернуть удалённый | Esc Назад"
+	TextFileRestored         = "Файл возвращён: %s"
+	HelpConflict             = "↑↓ Выбрать | Enter Подтвердить | Esc Разберусь сам"
+	HelpProfiles             = "↑↓ Выбрать | Enter Включить | Esc Назад"
+	HelpActivity             = "↑↓ Листать | Esc Назад"
+	LabelActivity            = "Журнал: что VibeGit делал с проектами"
+	TextNoActivity           = "Журнал пуст"
+	TextNoStashes            = "Отложенного нет"
+	TextStashApplied         = "Отложенное stash@{%d} возвращено"
+	TextStashPopped          = "Отложенное stash@{%d} возвращено и убрано из списка"
+	TextStashDropped         = "Отложенное stash@{%d} удалено"
+	LabelStashes             = "Отложенное (git stash):"
+	LabelBranches            = "Ветки:"
+	TextBranchSwitched       = "Ты на ветке %s"
+	TextBranchCreated        = "Ветка %s создана, ты на ней"
+	TextBranchCreatedFrom    = "Ветка %s начата от сейва %.7s, ты на ней"
+	PromptBranchFrom         = "Имя новой ветки от сейва %.7s:"
+	PromptTag                = "Тег для сейва %.7s:"
+	TextAlreadyOnBranch      = "Ты уже на ветке %s"
+	PromptBranch             = "Имя новой ветки (начнётся с текущего сейва):"
+	HelpBranches             = "↑↓ Выбрать | Enter Переключиться | N Новая ветка | Esc Назад"
+	HelpBranchInput          = "[Enter Создать] [Esc Отмена]"
+	HelpTagInput             = "[Enter Поставить] [Esc Отмена]"
+	HelpStashes              = "↑↓ Листать | A Вернуть | P Вернуть и убрать | X Удалить | Esc Назад"
+	TextHistoryProgress      = "Загружено сейвов: %d (Esc — прервать)"
+	TextHistoryCanceled      = "Загрузка истории прервана, показаны последние %d сейвов"
+	TextHistorySummary       = "Сейвов: %d · с %s по %s"
+	TextHistoryPartSummary   = "Загружено сейвов: %d · с %s по %s"
+	TextHistoryUnpushed      = " · не в облаке: %d"
+	TextUnpushedCheckpoints  = "У тебя %d %s в облаке %s"
+	TextShallowHistory       = "История обрезана (shallow clone). [U] Докачать всю историю"
+	TextUnshallowed          = "История докачана целиком"
+	TextStillShallow         = "Часть истории всё ещё не скачана"
+	HelpRefInput             = "[Enter Найти] [Esc Отмена]"
+	HelpRefActions           = "↑↓ Выбрать | Enter Погнали | Esc Назад"
+	PromptSearch             = "Что ищем? Слова из описания, автор, тег или хэш:"
+	HelpSearch               = "[↑↓ Выбрать] [Enter Откатиться] [Esc Отмена]"
+	TextNoMatches            = "Ничего не нашлось"
+	PromptRef                = "Куда прыгаем? Хэш, ветка, тег или HEAD~3:"
+	LabelProfiles            = "Профили настроек:"
+	LabelProfile             = "Профиль:"
+	LabelConflict            = "⚡ Облако и ты разошлись. Что делаем?"
+	LabelActions             = "Что делаем:"
+	LabelHistory             = "Твой флоу:"
+	LabelBranchHistory       = "Флоу ветки %s (только просмотр):"
+	TextHistoryReadOnly      = "Это история другой ветки: переключись на неё, чтобы откатываться и менять сейвы"
+	LabelFiles               = "Изменённые файлы:"
+	LabelBranch              = "Ветка:"
+	LabelScope               = "Папка: %s"
+	TextScopeHidden          = " (ещё файлов вне неё: %d, A — весь проект)"
+	TextScopeWhole           = " (A — только эта папка)"
+	LabelLastCommit          = "Последний сейв:"
+	LabelStaged              = "Готово к сейву:"
+	LabelModified            = "Изменилось:"
+	LabelUntracked           = "Новое:"
+	LabelDeleted             = "Удалено:"
+	LabelRenamed             = "Переименовано:"
+	LabelStaging             = "Что берём в сейв:"
+	LabelSubmodules          = "Субмодули:"
+	TextNoCheckpoints        = "Вайбов пока нет, начинай творить"
+	TextNoFiles              = "Изменений нет, всё уже в сейве"
+	TextSelectedCount        = "%d выбрано"
+	TextNoDiff               = "Изменений нет"
+	TextNoCommits            = "Нет моментов"
+	TextNothingToSave        = "Нечего сейвить: изменений нет. Нужна метка в истории? Жми [M]"
+	TextNothingToSquash      = "Схлопывать нечего: нужно хотя бы два сейва подряд после последнего ручного коммита или закреплённого сейва"
+	TextWorkDirGone          = "Дальше работать негде. Нажми q, чтобы выйти"
+	TextIdentityMissing      = "Git не знает, кто ты: user.name и user.email не заданы, сейвы уйдут под чужим именем"
+	HelpIdentityBanner       = "[I] Представиться [X] Больше не показывать"
+	TextMergeInProgress      = "Идёт слияние веток: разреши конфликты в файлах и заверши его или отмени"
+	TextMergeCommitted       = "Слияние сохранено: %.7s"
+	TextMergeAborted         = "Слияние отменено, файлы как до него"
+	TextReturnedToPresent    = "Вернулись в настоящее: %.7s"
+	TextConfirmCheckpoint    = "Сейв затронет файлов: %d (порог — %d). Точно всё это сейвим?"
+	HelpConfirmCheckpoint    = "[Y/Enter] Сейвим [N/Esc] Отмена"
+	TextConfirmCheckpointCLI = "Сейв затронет файлов: %d, больше порога checkpoint.confirmFiles (%d). Если так и задумано, добавь --yes"
+	TextCheckpointCanceled   = "Сейв отменён"
+	TextHookFailed           = "Хук %q не сработал: %s"
+	TextHookOutput           = "Хук %q: %s"
+	TextAutoCheckpointTooBig = "Автосейв пропущен: изменено файлов — %d, больше порога. Сейвни вручную, если всё верно"
+	TextInThePast            = "Ты в прошлом: ветка откачена с %.7s. [N] Вернуться в настоящее"
+	PromptIdentityName       = "Как тебя подписывать в git (user.name)?"
+	PromptIdentityEmail      = "Твой email для git (user.email)?"
+	HelpIdentityInput        = "[Enter Дальше] [Esc Отмена]"
+	TextIdentitySet          = "Git теперь знает тебя: %s <%s>"
+	LabelRecent              = "Недавние проекты:"
+	HelpRecent               = "↑↓ Выбрать | Enter Открыть | Esc Назад"
+	TextNoRecent             = "Других проектов пока не было"
+	TextRepositorySwitched   = "Открыт проект %s"
+	TextAutoCheckpoint       = "Автосейв: "
+	TextStateNotSaved        = "Вид не запомнился: %v"
+	TextShellFailed          = "Терминал завершился с ошибкой: %v"
+	TextEstimatedSize        = "Этот сейв добавит ~%s"
+	TextStagedOnlyHint       = "[S] Сохранить только подготовленное (%d)"
+	TextMoreSuggestions      = "Без быстрого выбора, только вписать: %s"
+	TextNoProfile            = "без профиля"
+	TitleSummaryRollback     = "Вот что произошло: откат"
+	TitleSummarySquash       = "Вот что произошло: схлопывание"
+	TitleSummaryMerge        = "Вот что произошло: слияние"
+	TitleSummaryForcePush    = "Вот что произошло: принудительная отправка"
+	TitleSummaryUndo         = "Вот что произошло: отмена сейва"
+	TextCheckpointUndone     = "Сейв «%s» отменён, его изменения остались в файлах"
+	TextNothingToUndo        = "Отменять нечего: это самый первый сейв"
+	TextReverted             = "Файлы как в сейве %.7s, откат записан сейвом %.7s, история на месте"
+	TextRollbackBackup       = "Правки до отката сохранены в ветке %s: [N] вернёт к ним, пока нет новых сейвов, а ветка хранит их и потом"
+	TextTakeTheirsBackup     = "Незасейвленные правки сохранены в ветке %s, переключиться на неё — [B]"
+	TextNothingToRevert      = "Файлы и так как в сейве %.7s"
+	TextSummaryHeads         = "Было %.7s → стало %.7s"
+	TextSummaryAffected      = "Больше не в истории: %d"
+	TextSummaryMore          = "…и ещё %d"
+	TextConflictOverwrites   = "Если оставить твою версию, из облака пропадут сейвы (%d):"
+	TextConflictNoForce      = "Оставить твою версию нельзя: для этого облако пришлось бы перезаписать, а принудительная отправка выключена (sync.forcePush)"
+	TextForcePushing         = "Обычная отправка не прошла, отправляю принудительно, в облаке перезапишется сейвов: %d..."
+	HelpSummary              = "Любая клавиша — закрыть"
+	LabelSyncResult          = "Синк с облаком:"
+	TextSyncReceived         = "пришло ↓%d"
+	TextSyncSent             = "ушло ↑%d"
+	TextSyncInSync           = "всё совпадает"
+	TextSyncForced           = " (принудительно)"
+	TextForcePushOverwrote   = "перезаписано чужих моментов: %d, прежняя версия облака — %.7s"
+	TextRebased              = "Копия получена, своих сейвов переложено поверх: %d"
+	TextSyncStrategy         = " · стратегия: %s"
+	TextSyncOverwritten      = "⚠ перезаписано %d %s %s, прежняя версия облака — %.7s"
+	TextSyncResolved         = "конфликт решён в пользу твоей версии"
+	LabelSyncLocal           = "локально"
+	LabelSyncRemote          = "облако"
+	TitlePreview             = "Откатиться к %.7s: %s?"
+	TextPreviewCounts        = "Изменится: %d, вернётся: %d, удалится: %d"
+	TextPreviewCommits       = "Сейвов пропадёт из истории ветки: %d"
+	TextPreviewNothing       = "Файлы не изменятся"
+	TextChainIntact          = "Цепочка цела: проверено сейвов — %d"
+	TextChainEmpty           = "В истории нет сейвов с цепочкой, включи checkpoint.chain в настройках"
+	TextPreviewUnpushed      = "%d несохранённых в облаке моментов будут потеряны"
+	HelpPreview              = "Enter/Y Перемотать (стереть) | R Откатить (сохранить историю) | Esc/N Отмена"
+	HelpPreviewUnsaved       = "[y/n] Y Перемотать, потеряв правки | R Откатить (сохранить историю) | Esc/N Отмена"
+	TextPreviewUnsavedLost   = "Вернуться к этому вайбу? Несохранённые изменения пропадут (файлов: %d)"
+	TextPreviewUnsavedSaved  = "Несохранённые изменения (файлов: %d) сначала сохранятся сейвом «Перед откатом»"
+	TextNoProfiles           = "Профилей нет: добавь их в \"profiles\" в настройках"
+	TextProfileActive        = "Профиль: %s"
+	TextDiffPosition         = "строки %d-%d из %d"
+	TextCurrent              = " (текущий вайб)"
+	TextPinnedMarker         = " 📌"
+	TextNoteMarker           = " 📝"
+	TextCompareBaseMarker    = " [база]"
+	TextCompareHint          = "База сравнения %.7s: выбери другой сейв и нажми D, Пробел снимает отметку"
+	TextCompareSame          = "Это и есть база сравнения, выбери другой сейв"
+	TextNoteSaved            = "Заметка к сейву %.7s сохранена"
+	TextTagged               = "Сейв %.7s отмечен тегом %s"
+	TextNoteRemoved          = "Заметка к сейву %.7s удалена"
+	TextNotesNotPushed       = "заметки не отправлены: %v"
+	LabelNote                = "Заметка:"
+	TextPinned               = "Сейв %.7s закреплён: схлопывание его не тронет"
+	TextUnpinned             = "Сейв %.7s больше не закреплён"
+	TextClean                = "✓ Ты в потоке. Всё чисто."
+	TextDirty                = "⚡ Есть незасейвленный прогресс"
+	TextLoading              = "В процессе: "
+
+	TextSubmodulesWarning = "⚠ Содержимое субмодулей не попадает в сейв"
+	TextSubmoduleNotInit  = " (не инициализирован)"
+	TextLargeFile         = " (большой файл, сверен по размеру и дате)"
+	TextSubmoduleChanged  = " (другой коммит)"
+	TextSubmodulesUpdated = "Субмодули подтянуты"
 )
 
 // Error messages
 const (
-	ErrFailedToAddFiles         = "не удалось добавить файлы"
-	ErrFailedToCreateCheckpoint = "не удалось зафиксировать момент"
-	ErrFailedToOpenRepo         = "не удалось открыть проект"
-	ErrFailedToGetWorktree      = "не удалось получить рабочую папку"
-	ErrFailedToGetStatus        = "не удалось получить статус"
-	ErrFailedToGetHead          = "не удалось получить текущий момент"
-	ErrFailedToCommit           = "не удалось сохранить решение конфликта"
-	ErrFailedToAddChanges       = "не удалось добавить изменения"
-	ErrFailedToPush             = "не удалось отправить копию"
-	ErrNoRemote                 = "Удаленное хранилище не найдено. Это только локальная версия."
-	ErrAlreadyUpToDate          = "Всё актуально"
-	ErrConflictsDetected        = "Конфликты решены автоматически"
-	ErrForcePushSuccess         = "Копия отправлена принудительно"
-	ErrPushSuccess              = "Копия отправлена успешно"
-	ErrPullSuccess              = "Копия получена успешно"
+	ErrFailedToAddFiles           = "не удалось добавить файлы"
+	ErrFailedToCreateCheckpoint   = "не удалось зафиксировать момент"
+	ErrFailedToOpenRepo           = "не удалось открыть проект"
+	ErrFailedToGetWorktree        = "не удалось получить рабочую папку"
+	ErrFailedToGetStatus          = "не удалось получить статус"
+	ErrFailedToGetHead            = "не удалось получить текущий момент"
+	ErrFailedToUndo               = "не удалось отменить сейв"
+	ErrUndoPinned                 = "сейв %.7s закреплён 📌: сними закрепление (P в истории), чтобы его отменить"
+	ErrFailedToRevert             = "не удалось откатить с сохранением истории"
+	ErrRevertUnsaved              = "откат перепишет файлы с несохранёнными правками, сначала засейвь их: %s"
+	ErrFailedToCommit             = "не удалось сохранить решение конфликта"
+	ErrFailedToAddChanges         = "не удалось добавить изменения"
+	ErrFailedToPush               = "не удалось отправить копию"
+	ErrFailedToPull               = "не удалось забрать изменения из облака"
+	ErrFailedToUpdateSubmodules   = "не удалось подтянуть субмодули"
+	ErrFailedToUnstage            = "не удалось убрать файлы из сейва"
+	ErrFailedToRestoreFile        = "не удалось вернуть файл"
+	ErrFailedToStage              = "не удалось добавить файлы в сейв"
+	ErrFailedToBuildDiff          = "не удалось собрать изменения"
+	ErrLinkedWorktreeUnsupported  = "связанные рабочие деревья (git worktree) не поддерживаются"
+	ErrFailedToSquash             = "не удалось схлопнуть сейвы"
+	ErrFailedToPin                = "не удалось закрепить сейв"
+	ErrFailedToTag                = "не удалось поставить тег"
+	ErrHookTimeout                = "хук не уложился в 30 секунд и остановлен"
+	ErrFailedToReturn             = "не удалось вернуться в настоящее"
+	ErrNoPresent                  = "возвращаться некуда: ты и так в настоящем"
+	ErrPresentUnsaved             = "есть незасейвленные правки, они пропадут при возвращении. Сейвни их или откати"
+	ErrFailedToSaveBeforeRollback = "не удалось сохранить правки перед откатом, откат отменён"
+	ErrFailedToMerge              = "не удалось завершить слияние"
+	ErrFailedToAbortMerge         = "не удалось отменить слияние"
+	ErrNoMergeInProgress          = "слияние не идёт"
+	ErrMergeUnresolved            = "в файлах остались метки конфликта (<<<<<<< / >>>>>>>): %s"
+	ErrFailedToSaveNote           = "не удалось сохранить заметку"
+	ErrFailedToReadNotes          = "не удалось прочитать заметки"
+	ErrInvalidAuthorEmail         = "некорректный email автора"
+	ErrInvalidDate                = "не понял дату %q, нужно 2006-01-02, \"2006-01-02 15:04\" или RFC 3339"
+	ErrDateInFuture               = "дата сейва %s ещё не наступила"
+	ErrFailedToReadActivity       = "не удалось прочитать журнал"
+	ErrFailedToPreview            = "не удалось прикинуть последствия отката"
+	ErrFailedToSwitchRepo         = "не удалось открыть проект"
+	ErrFailedToBlame              = "не удалось собрать авторство строк"
+	ErrFileNotInCheckpoint        = "файла %s нет в сейве %.7s"
+	ErrFailedToSetIdentity        = "не удалось сохранить имя в git"
+	ErrEmptyIdentityName          = "имя не может быть пустым"
+	ErrFailedToVerifyChain        = "не удалось проверить цепочку сейвов"
+	ErrChainBroken                = "цепочка сейвов нарушена перед %.7s (%s): предыдущий сейв изменён или подменён"
+	ErrFailedToReadStash          = "не удалось прочитать отложенное"
+	ErrFailedToListBranches       = "не удалось получить список веток"
+	ErrFailedToSwitchBranch       = "не удалось переключить ветку"
+	ErrFailedToCreateBranch       = "не удалось создать ветку"
+	ErrSwitchBranchDirty          = "есть незасейвленные изменения, сейвни их перед переходом на %s"
+	ErrBranchExists               = "ветка %q уже есть"
+	ErrTagExists                  = "тег %q уже есть, выбери другое имя"
+	ErrSwitchBranchUntracked      = "новый файл %s затёрся бы файлом из ветки, сейвни или перенеси его"
+	ErrBranchWithoutCheckpoint    = "ветке не с чего начаться: сначала сделай первый сейв"
+	ErrFailedToDropStash          = "не удалось удалить отложенное"
+	ErrFailedToApplyStash         = "не удалось вернуть отложенное"
+	ErrStashNotFound              = "нет отложенного stash@{%d}"
+	ErrStashWouldOverwrite        = "отложенное затрёт твои незасейвленные изменения, сначала сейвни их"
+	ErrNotAStash                  = "это не запись stash"
+	ErrFailedToUnshallow          = "не удалось докачать историю"
+	ErrParentMissing              = "предыдущий сейв не скачан: история обрезана (shallow clone), докачай её клавишей U в истории"
+	ErrRefNotFound                = "не нашёл сейв"
+	ErrInvalidBranchName          = "недопустимое имя ветки %q"
+	ErrInvalidTagName             = "недопустимое имя тега %q"
+	ErrBranchNotFound             = "ветки %q нет ни здесь, ни в облаке"
+	ErrRefAmbiguous               = "несколько сейвов начинаются с %q, допиши ещё символов: %s"
+	ErrWorkDirUnavailable         = "Рабочая папка недоступна: её удалили или на неё больше нет прав"
+	ErrDirNotFound                = "папка %s не найдена"
+	ErrNotADirectory              = "%s — не папка"
+	ErrNoRemote                   = "Удаленное хранилище не найдено. Это только локальная версия."
+	ErrRemoteNotFoundIn           = "Удаленного хранилища %q нет, есть только: %s. Укажи нужное в sync.remote"
+	ErrRemoteUnreachable          = "не могу достучаться до облака"
+	ErrRemoteBadCredentials       = "неверные данные для входа в облако"
+	ErrAlreadyUpToDate            = "Всё актуально"
+	ErrConflictsDetected          = "Локальные изменения сохранены поверх удалённых"
+	ErrConflictManual             = "Синк отменён, разберись с конфликтом вручную. Файлы изменились и у тебя, и в облаке"
+	ErrConflictNoOverlap          = "Синк отменён, разберись с конфликтом вручную. Общих изменённых файлов нет"
+	ErrFailedToFetch              = "не удалось забрать версию из облака"
+	ErrTookTheirs                 = "Взяли версию из облака"
+	ErrFailedToTakeTheirs         = "не удалось переключиться на версию из облака"
+	ErrTakeTheirsUnsaved          = "есть незасейвленные правки, версия из облака их затрёт. Сейвни их или включи rollback.autoSaveBefore"
+	ErrForcePushSuccess           = "Копия отправлена принудительно"
+	ErrForcePushForbidden         = "Облако не принимает сейвы, а принудительная отправка в эту ветку запрещена настройками"
+	ErrPushRejected               = "Облако не принимает сейвы, а эта стратегия синка ничего в нём не перезаписывает"
+	ErrPushRejectedNewer          = "Облако не принимает сейвы: там есть новые сейвы, которых у тебя нет (%d). Принудительная отправка перезаписала бы их, поэтому она выключена — включи sync.forcePush, если это и нужно"
+	ErrSyncDiverged               = "Сейвы появились и у тебя, и в облаке. Стратегия ff-only ничего не трогает: выбери merge или rebase в sync.strategy"
+	ErrRebaseClash                = "Синк отменён: у тебя и в облаке изменились одни и те же файлы, rebase их не объединяет"
+	ErrFailedToRebase             = "не удалось переложить сейвы поверх облачных"
+	ErrRebaseUnsaved              = "засейвь правки перед синком: rebase перекладывает только сейвы"
+	ErrRebaseUnrelated            = "у твоей истории и облачной нет общего начала"
+	ErrRebaseMerges               = "среди новых сейвов есть слияние веток, rebase такое не перекладывает"
+	ErrMoveUntracked              = "новый файл %s мешает, его перезапишет засейвленная версия"
+	ErrUnknownStrategy            = "неизвестная стратегия синка %q: нужна merge, rebase или ff-only"
+	ErrPushSuccess                = "Копия отправлена успешно"
+	ErrPullSuccess                = "Копия получена успешно"
+)
+
+// Error categories and friendly explanations of common git failures
+const (
+	ErrCategoryNetwork = "Сеть"
+	ErrCategoryAuth    = "Доступ"
+	ErrCategoryRepo    = "Проект"
+
+	ErrHintAuthRequired   = "облако требует авторизацию: проверь SSH-ключ или токен"
+	ErrHintAuthFailed     = "облако отказало в доступе: у ключа или токена нет прав на этот репозиторий"
+	ErrHintRemoteNotFound = "репозиторий в облаке не найден: проверь адрес remote"
+	ErrHintRemoteEmpty    = "репозиторий в облаке пока пустой"
+	ErrHintUnreachable    = "облако недоступно: проверь интернет, VPN или адрес сервера"
+	ErrHintTimeout        = "облако не ответило вовремя, попробуй ещё раз"
+	ErrHintNotRepo        = "здесь нет git-репозитория"
+	ErrHintNoRemote       = "у проекта не настроен remote"
+	ErrHintRefNotFound    = "ветка или момент не найдены, возможно, их уже удалили"
+	ErrHintObjectMissing  = "в репозитории не хватает данных, он может быть повреждён"
+	ErrHintNonFastForward = "история в облаке ушла вперёд, сначала нужно забрать изменения"
+	ErrHintDirtyWorktree  = "есть незасейвленные изменения, сначала сделай сейв"
+	ErrHintEmptyCommit    = "нечего сохранять: изменений нет"
 )
 
 // Time machine author info
 const (
-	CheckpointAuthorName  = "Машина Времени"
-	CheckpointAuthorEmail = "timemachine@local"
-	ConflictAuthorName    = "Time Machine TUI"
-	ConflictAuthorEmail   = "timemachine@local"
+	CheckpointAuthorName     = "Машина Времени"
+	CheckpointAuthorEmail    = "timemachine@local"
+	DefaultCheckpointMessage = "Сейв без описания"
+	AutoCheckpointMessage    = "Автосейв"
+	PreRollbackMessage       = "Перед откатом к %.7s"
+	ConflictAuthorName       = "Time Machine TUI"
+	ConflictAuthorEmail      = "timemachine@local"
+	ConflictCommitMessage    = "Локальные изменения сохранены поверх удалённых"
+	NotesCommitMessage       = "Notes added by 'git notes add'"
+	MergeCommitMessage       = "Слияние веток"
+	RevertCommitMessage      = "Откат к %.7s: %s"
 )
 
+// QuickPickCount is how many suggestions can be picked with the 1-9 keys
+const QuickPickCount = 9
+
 // Default description suggestions
 var DefaultSuggestions = []string{
 	"Поймал волну 🌊",
@@ -177,6 +1392,10 @@ func GetMenuItems() []string {
 		MenuViewHistory,
 		MenuRollback,
 		MenuSync,
+		MenuSaveAndSync,
+		MenuViewChanges,
+		MenuCreateMarker,
+		MenuSquash,
 	}
 }
 
@@ -185,10 +1404,26 @@ func (m *Model) GetMenuItems() []string {
 	if m.GitNotInitialized {
 		return []string{MenuInitGit}
 	}
-	return []string{
+	items := []string{
 		MenuCreateCheckpoint,
 		MenuViewHistory,
 		MenuRollback,
 		MenuSync,
+		MenuSaveAndSync,
+		MenuViewChanges,
+		MenuCreateMarker,
+		MenuSquash,
+		MenuBranches,
+	}
+	if m.Status != nil && len(m.Status.Submodules) > 0 {
+		items = append(items, MenuUpdateSubmodules)
+	}
+	if m.Status != nil && m.Status.Present != "" {
+		items = append([]string{MenuReturnToPresent}, items...)
+	}
+	// Finishing the merge comes before anything else
+	if m.Status != nil && m.Status.MergeInProgress {
+		items = append([]string{MenuMergeContinue, MenuMergeAbort}, items...)
 	}
+	return items
 }
@@ -0,0 +1,152 @@
+package timekeeper
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+
+	"time-machine/internal/config"
+	"time-machine/internal/models"
+)
+
+// activityFileName is the append-only operations log inside the config dir
+const activityFileName = "activity.jsonl"
+
+// activityViewLimit caps how many of the latest entries the journal shows
+const activityViewLimit = 200
+
+// activityPath returns where the activity log lives, empty when there is
+// no config dir to keep it in
+func activityPath() string {
+	dir, err := config.Dir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, activityFileName)
+}
+
+// record appends the outcome of an operation to the activity log. Logging
+// must never break the operation itself, so failures are ignored.
+func (s *Service) record(action string, msg tea.Msg) {
+	// Shutdown waits for the entry to hit the disk
+	s.activityMu.Lock()
+	defer s.activityMu.Unlock()
+
+	entry := models.ActivityEntry{
+		Time:   time.Now(),
+		Action: action,
+	}
+	entry.Repo, _ = s.workDir()
+
+	switch msg := msg.(type) {
+	case models.CheckpointCreatedMsg:
+		entry.Success, entry.Outcome = msg.Success, msg.Message
+	case models.RollbackMsg:
+		entry.Success, entry.Outcome = msg.Success, msg.Message
+	case models.SyncMsg:
+		entry.Success, entry.Outcome = msg.Success, msg.Message
+		if msg.Summary != nil {
+			entry.Action = models.ActivityForcePush
+		}
+	case models.StatusMsg:
+		entry.Success, entry.Outcome = true, msg.Text
+	case models.ConflictChoiceMsg:
+		entry.Outcome = msg.Reason
+	case models.ErrMsg:
+		entry.Outcome = Explain(msg.Error).Error()
+	default:
+		entry.Outcome = fmt.Sprintf("%T", msg)
+	}
+
+	// The journal tails the log live, even when it can't be written
+	if s.activityFeed != nil {
+		s.activityFeed(entry)
+	}
+
+	path := activityPath()
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.Write(append(data, '\n'))
+}
+
+// SetActivityFeed registers a callback receiving every activity entry as
+// soon as it is recorded
+func (s *Service) SetActivityFeed(fn func(entry models.ActivityEntry)) {
+	s.activityFeed = fn
+}
+
+// LoadActivity reads the latest entries of the activity log, newest first
+func (s *Service) LoadActivity() tea.Msg {
+	path := activityPath()
+	if path == "" {
+		return models.ActivityLoadedMsg{}
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return models.ActivityLoadedMsg{}
+	}
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToReadActivity), err)}
+	}
+	defer file.Close()
+
+	var entries []models.ActivityEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry models.ActivityEntry
+		// A torn line from a crash shouldn't hide the rest of the log
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) > activityViewLimit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToReadActivity), err)}
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return models.ActivityLoadedMsg{Entries: entries}
+}
+
+// shutdownTimeout caps how long Shutdown waits for pending writes
+const shutdownTimeout = 2 * time.Second
+
+// Shutdown waits until the activity entry being written is on disk, so
+// quitting right after an operation doesn't lose its record. The log stays
+// locked afterwards, operations still running can't leave half a line.
+func (s *Service) Shutdown() {
+	done := make(chan struct{})
+	go func() {
+		s.activityMu.Lock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+	}
+}
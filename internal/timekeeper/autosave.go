@@ -0,0 +1,83 @@
+package timekeeper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+
+	"time-machine/internal/models"
+)
+
+// AutoCheckpointInterval returns how often to auto-save, 0 when disabled
+func (s *Service) AutoCheckpointInterval() time.Duration {
+	return time.Duration(s.config.Checkpoint.AutoMinutes) * time.Minute
+}
+
+// AutoCheckpoint saves all changes when at least one of them is meaningful,
+// i.e. neither excluded from auto-saves nor noise. Returns nil when there
+// was nothing worth saving.
+func (s *Service) AutoCheckpoint() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		// Not a repository, nothing to auto-save
+		return nil
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+
+	if !s.hasMeaningfulChanges(status) {
+		return nil
+	}
+
+	description := models.T(models.AutoCheckpointMessage)
+	if s.config.Checkpoint.DefaultMessage != "" {
+		description = s.DefaultMessage()
+	}
+
+	msg := s.CreateCheckpoint(description, CheckpointOptions{
+		SkipUntracked: !s.config.Checkpoint.IncludeUntracked,
+	})
+	if confirm, ok := msg.(models.ConfirmCheckpointMsg); ok {
+		// Nobody is there to confirm, leave it to a manual checkpoint
+		return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextAutoCheckpointTooBig), confirm.Files)}
+	}
+	if created, ok := msg.(models.CheckpointCreatedMsg); ok {
+		if !created.Success {
+			return nil
+		}
+		return models.StatusMsg{Text: models.T(models.TextAutoCheckpoint) + created.Message}
+	}
+	return msg
+}
+
+// hasMeaningfulChanges reports whether any changed file may trigger an
+// auto-save on its own
+func (s *Service) hasMeaningfulChanges(status git.Status) bool {
+	for file, entry := range status {
+		if entry.Staging == git.Unmodified && entry.Worktree == git.Unmodified {
+			continue
+		}
+		if s.config.Checkpoint.IsAutoExcluded(file) || s.config.Status.IsNoise(file) {
+			continue
+		}
+		return true
+	}
+	return false
+}
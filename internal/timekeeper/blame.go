@@ -0,0 +1,62 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// blameAuthorWidth is how many characters of the author name blame shows
+const blameAuthorWidth = 14
+
+// BlameAtCommit shows who last changed every line of path as the file was
+// in the checkpoint hash, not as it is now
+func (s *Service) BlameAtCommit(hash, path string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBlame), err)}
+	}
+
+	result, err := git.Blame(commit, path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrFileNotInCheckpoint), path, hash)}
+	}
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBlame), err)}
+	}
+
+	var b strings.Builder
+	for _, line := range result.Lines {
+		author := []rune(line.AuthorName)
+		if len(author) > blameAuthorWidth {
+			author = author[:blameAuthorWidth]
+		}
+		fmt.Fprintf(&b, "%.7s %-*s %s │ %s\n",
+			line.Hash, blameAuthorWidth, string(author), line.Date.Format("2006-01-02"), line.Text)
+	}
+
+	return models.DiffMsg{
+		Title: fmt.Sprintf(models.T(models.TitleBlame), path, hash),
+		Patch: b.String(),
+	}
+}
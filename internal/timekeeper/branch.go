@@ -0,0 +1,318 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// ListBranches returns the local branches sorted by name, the checked out
+// one flagged
+func (s *Service) ListBranches() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	iter, err := repo.Branches()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToListBranches), err)}
+	}
+	defer iter.Close()
+
+	current := branchName(repo)
+	branches := []models.BranchEntry{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		entry := models.BranchEntry{
+			Name:    ref.Name().Short(),
+			Hash:    ref.Hash().String(),
+			Current: ref.Name().Short() == current,
+		}
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			entry.Date = commit.Author.When
+		}
+		branches = append(branches, entry)
+		return nil
+	})
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToListBranches), err)}
+	}
+
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return models.BranchesLoadedMsg{Branches: branches}
+}
+
+// SwitchBranch checks out the local branch name. Changes that aren't
+// checkpointed would be lost or mixed into the other branch, so the switch
+// is refused while there are any. New files stay where they are.
+func (s *Service) SwitchBranch(name string) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityBranch, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	if name == branchName(repo) {
+		return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextAlreadyOnBranch), name)}
+	}
+
+	ref := plumbing.NewBranchReferenceName(name)
+	if _, err := repo.Reference(ref, false); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrBranchNotFound), name)}
+	}
+
+	untracked, clean, err := untrackedIfClean(worktree)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+	if !clean {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrSwitchBranchDirty), name)}
+	}
+
+	if err := checkoutBranch(repo, worktree, ref, untracked); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSwitchBranch), err)}
+	}
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextBranchSwitched), name)}
+}
+
+// untrackedIfClean returns the untracked files of the worktree, clean is
+// false when it has changes that aren't checkpointed
+func untrackedIfClean(worktree *git.Worktree) (untracked map[string]bool, clean bool, err error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, false, err
+	}
+	untracked = map[string]bool{}
+	for file, entry := range status {
+		if entry.Worktree == git.Untracked {
+			untracked[file] = true
+			continue
+		}
+		if entry.Staging != git.Unmodified || entry.Worktree != git.Unmodified {
+			return nil, false, nil
+		}
+	}
+	return untracked, true, nil
+}
+
+// checkoutBranch switches a clean worktree to the branch ref. Checkout in
+// go-git deletes every file it doesn't track, ignored ones included, so only
+// the files that differ between the two commits are written here. An
+// untracked file the branch would overwrite stops the switch instead.
+func checkoutBranch(repo *git.Repository, worktree *git.Worktree, ref plumbing.ReferenceName, untracked map[string]bool) error {
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	target, err := repo.Reference(ref, true)
+	if err != nil {
+		return err
+	}
+	from, err := commitTree(repo, head.Hash())
+	if err != nil {
+		return err
+	}
+	to, err := commitTree(repo, target.Hash())
+	if err != nil {
+		return err
+	}
+
+	files, err := treeChanges(from, to)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.file != nil && untracked[file.path] {
+			return fmt.Errorf(models.T(models.ErrSwitchBranchUntracked), file.path)
+		}
+	}
+	if err := writeFiles(worktree.Filesystem.Root(), files); err != nil {
+		return err
+	}
+
+	// The files are in place, point HEAD at the branch and match the index
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, ref)); err != nil {
+		return err
+	}
+	return worktree.Reset(&git.ResetOptions{Commit: target.Hash(), Mode: git.MixedReset})
+}
+
+// treeChanges lists the files that differ between the trees from and to,
+// with their version in to
+func treeChanges(from, to *object.Tree) ([]stashFile, error) {
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]stashFile, 0, len(changes))
+	for _, change := range changes {
+		_, file, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+		name := change.To.Name
+		if file == nil {
+			name = change.From.Name
+		}
+		files = append(files, stashFile{path: name, file: file})
+	}
+	return files, nil
+}
+
+// writeFiles puts files into the worktree at root. Like git, it doesn't
+// leave behind the directories emptied by deleted files.
+func writeFiles(root string, files []stashFile) error {
+	for _, file := range files {
+		if err := file.restore(root); err != nil {
+			return err
+		}
+		if file.file == nil {
+			for dir := path.Dir(file.path); dir != "."; dir = path.Dir(dir) {
+				if os.Remove(filepath.Join(root, filepath.FromSlash(dir))) != nil {
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// commitTree returns the tree of the commit hash
+func commitTree(repo *git.Repository, hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// CreateBranch starts the branch name at the current checkpoint and
+// switches to it. Nothing changes in the files, so unsaved changes simply
+// come along to the new branch.
+func (s *Service) CreateBranch(name string) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityBranch, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	ref := plumbing.NewBranchReferenceName(name)
+	if err := ref.Validate(); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrInvalidBranchName), name)}
+	}
+	if _, err := repo.Reference(ref, false); err == nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrBranchExists), name)}
+	}
+
+	// A branch needs a checkpoint to start from
+	if headHash(repo).IsZero() {
+		return models.ErrMsg{Error: errors.New(models.T(models.ErrBranchWithoutCheckpoint))}
+	}
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: ref, Create: true, Keep: true}); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToCreateBranch), err)}
+	}
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextBranchCreated), name)}
+}
+
+// CreateBranchFromCheckpoint starts the branch name at the checkpoint hash
+// and switches to it, e.g. to pick an old idea up again. The files change
+// to the checkpoint's, so like a switch it needs everything checkpointed.
+func (s *Service) CreateBranchFromCheckpoint(hash, name string) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityBranch, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	ref := plumbing.NewBranchReferenceName(name)
+	if err := ref.Validate(); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrInvalidBranchName), name)}
+	}
+	if _, err := repo.Reference(ref, false); err == nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrBranchExists), name)}
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToCreateBranch), err)}
+	}
+
+	untracked, clean, err := untrackedIfClean(worktree)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+	if !clean {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrSwitchBranchDirty), name)}
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(ref, commit.Hash)); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToCreateBranch), err)}
+	}
+	if err := checkoutBranch(repo, worktree, ref, untracked); err != nil {
+		// Don't leave a branch behind that the user never got onto
+		_ = repo.Storer.RemoveReference(ref)
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToCreateBranch), err)}
+	}
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextBranchCreatedFrom), name, hash)}
+}
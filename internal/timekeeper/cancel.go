@@ -0,0 +1,31 @@
+package timekeeper
+
+import (
+	"context"
+)
+
+// cancelable starts an operation Cancel can interrupt. The returned func
+// must be called when the operation ends.
+func (s *Service) cancelable() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.cancelMu.Lock()
+	s.cancel = cancel
+	s.cancelMu.Unlock()
+
+	return ctx, func() {
+		s.cancelMu.Lock()
+		s.cancel = nil
+		s.cancelMu.Unlock()
+		cancel()
+	}
+}
+
+// Cancel interrupts the running cancelable operation, if any
+func (s *Service) Cancel() {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
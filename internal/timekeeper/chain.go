@@ -0,0 +1,105 @@
+package timekeeper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// chainTrailer is the message trailer holding a checkpoint's chain link
+const chainTrailer = "Vibegit-Chain: "
+
+// chainLink returns the trailer value for a checkpoint made on top of
+// parent: a hash of the parent's content and of the parent's own link, so
+// rewriting any chained checkpoint breaks every link after it. A nil parent
+// starts the chain.
+func chainLink(parent *object.Commit) string {
+	tree, previous := plumbing.ZeroHash, ""
+	if parent != nil {
+		tree, previous = parent.TreeHash, chainValue(parent.Message)
+	}
+	sum := sha256.Sum256([]byte(tree.String() + " " + previous))
+	return hex.EncodeToString(sum[:])
+}
+
+// chainValue extracts the chain trailer from a commit message, empty when
+// the commit isn't chained
+func chainValue(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if value, found := strings.CutPrefix(line, chainTrailer); found {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// withChainTrailer appends the chain link for a checkpoint on top of HEAD
+// to the description
+func withChainTrailer(repo *git.Repository, description string) (string, error) {
+	var parent *object.Commit
+	if head, err := repo.Head(); err == nil {
+		if parent, err = repo.CommitObject(head.Hash()); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s\n\n%s%s\n", strings.TrimRight(description, "\n"), chainTrailer, chainLink(parent)), nil
+}
+
+// VerifyChain walks the first-parent history and checks the link of every
+// chained checkpoint against its parent. Checkpoints without a link, like
+// commits made by other tools, are skipped.
+func (s *Service) VerifyChain() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	head, err := repo.Head()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToVerifyChain), err)}
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToVerifyChain), err)}
+	}
+
+	verified := 0
+	for commit != nil {
+		var parent *object.Commit
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				// The rest of a shallow clone is not here to check
+				break
+			}
+		}
+
+		if value := chainValue(commit.Message); value != "" {
+			if value != chainLink(parent) {
+				return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrChainBroken), commit.Hash, firstLine(commit.Message))}
+			}
+			verified++
+		}
+		commit = parent
+	}
+
+	if verified == 0 {
+		return models.StatusMsg{Text: models.T(models.TextChainEmpty)}
+	}
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextChainIntact), verified)}
+}
@@ -0,0 +1,313 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// ResolveConflict finishes a sync whose pull was rejected, the way the user
+// chose: keep local work, take the remote branch, or stop and show which
+// files clash so the conflict can be sorted out by hand.
+func (s *Service) ResolveConflict(choice models.ConflictChoice) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityConflict, msg) }()
+
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	remote, err := repo.Remote(s.config.Sync.Remote)
+	if err != nil {
+		return models.SyncMsg{Success: false, Message: s.missingRemote(repo)}
+	}
+
+	switch choice {
+	case models.ConflictKeepMine:
+		if !s.config.Sync.ForcePush {
+			return models.SyncMsg{Success: false, Message: fmt.Sprintf(models.T(models.ErrPushRejectedNewer), s.wouldOverwrite(repo).Affected), Conflict: true}
+		}
+		if !s.config.Sync.CanForcePush(branchName(repo)) {
+			return models.SyncMsg{Success: false, Message: models.T(models.ErrForcePushForbidden), Conflict: true}
+		}
+		if err := s.keepLocalChanges(repo, worktree); err != nil {
+			return models.ErrMsg{Error: err}
+		}
+
+		summary, err := s.forcePush(repo, remote)
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPush), err)}
+		}
+		return models.SyncMsg{
+			Success:  true,
+			Message:  models.T(models.ErrConflictsDetected) + " · " + models.T(models.ErrForcePushSuccess) + overwrittenNote(summary),
+			Pushed:   true,
+			Forced:   true,
+			Conflict: true,
+			Sent:     countNew(repo, headHash(repo), plumbing.NewHash(summary.OldHead)),
+			Summary:  summary,
+		}
+
+	case models.ConflictTakeTheirs:
+		remoteHash, err := s.fetchRemoteBranch(repo, remote)
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToFetch), err)}
+		}
+
+		// The reset wipes unsaved changes, keep them like a rollback does
+		var backup string
+		if s.config.Rollback.AutoSaveBefore {
+			if backup, err = s.saveBeforeRollback(repo, worktree, remoteHash.String()); err != nil {
+				return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSaveBeforeRollback), err)}
+			}
+		} else if status, err := worktree.Status(); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+		} else if !status.IsClean() {
+			return models.ErrMsg{Error: errors.New(models.T(models.ErrTakeTheirsUnsaved))}
+		}
+
+		s.report(models.T("Переключаюсь на версию из облака..."))
+		err = worktree.Reset(&git.ResetOptions{
+			Commit: remoteHash,
+			Mode:   git.HardReset,
+		})
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToTakeTheirs), err)}
+		}
+		return models.SyncMsg{
+			Success:  true,
+			Message:  fmt.Sprintf("%s: %.7s", models.T(models.ErrTookTheirs), remoteHash),
+			Pulled:   true,
+			Conflict: true,
+			Backup:   backup,
+		}
+	}
+
+	// Manual: nothing was merged, so there is nothing to abort. Show the
+	// files both sides touched to make the manual merge less of a guess.
+	remoteHash, err := s.fetchRemoteBranch(repo, remote)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToFetch), err)}
+	}
+	files, err := clashingFiles(repo, worktree, remoteHash)
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	message := models.T(models.ErrConflictNoOverlap)
+	if len(files) > 0 {
+		message = models.T(models.ErrConflictManual) + ": " + strings.Join(files, ", ")
+	}
+	return models.SyncMsg{Success: false, Message: message, Conflict: true}
+}
+
+// keepLocalChanges commits whatever is uncommitted so the local state can be
+// pushed over the remote one
+func (s *Service) keepLocalChanges(repo *git.Repository, worktree *git.Worktree) error {
+	s.report(models.T("Оставляю локальные изменения поверх удалённых..."))
+
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)
+	}
+	if status.IsClean() {
+		return nil
+	}
+
+	if _, err := worktree.Add("."); err != nil {
+		return fmt.Errorf("%s: %w", models.T(models.ErrFailedToAddChanges), err)
+	}
+
+	// Commit the local state as the user, nothing was actually merged
+	_, err = worktree.Commit(models.T(models.ConflictCommitMessage), &git.CommitOptions{
+		Author: s.signature(repo, models.ConflictAuthorName, models.ConflictAuthorEmail),
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", models.T(models.ErrFailedToCommit), err)
+	}
+	return nil
+}
+
+// forcePush overwrites the remote branch with HEAD and recaps which remote
+// commits were discarded. The remote tip is fetched first, the
+// remote-tracking ref may be stale and miss what others pushed meanwhile.
+func (s *Service) forcePush(repo *git.Repository, remote *git.Remote) (*models.OperationSummary, error) {
+	oldRemote, err := s.fetchRemoteBranch(repo, remote)
+	if err != nil {
+		oldRemote = remoteBranchHash(repo, s.config.Sync.Remote)
+	}
+
+	s.report(models.T("Отправляю принудительно..."))
+	err = s.withRetry(models.T("Отправляю принудительно"), func() error {
+		return remote.Push(&git.PushOptions{
+			RemoteName: s.config.Sync.Remote,
+			Force:      true,
+		})
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, err
+	}
+	return forcePushSummary(repo, oldRemote), nil
+}
+
+// wouldOverwrite recaps the remote commits a force push of HEAD would
+// discard, as far as the remote-tracking ref knows. The failed pull or push
+// before it has just refreshed the ref.
+func (s *Service) wouldOverwrite(repo *git.Repository) *models.OperationSummary {
+	return summarize(repo, models.T(models.TitleSummaryForcePush), remoteBranchHash(repo, s.config.Sync.Remote), headHash(repo))
+}
+
+// overwrittenNote tells how many remote commits a force push discarded and
+// where they can be found, empty when none were
+func overwrittenNote(summary *models.OperationSummary) string {
+	if summary == nil || summary.Affected == 0 {
+		return ""
+	}
+	return "; " + fmt.Sprintf(models.T(models.TextForcePushOverwrote), summary.Affected, summary.OldHead)
+}
+
+// fetchRemoteBranch updates the remote-tracking refs and returns the commit
+// the current branch has on the remote
+func (s *Service) fetchRemoteBranch(repo *git.Repository, remote *git.Remote) (plumbing.Hash, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	s.report(models.T("Забираю версию из облака..."))
+	err = s.withRetry(models.T("Забираю версию"), func() error {
+		return remote.Fetch(&git.FetchOptions{RemoteName: s.config.Sync.Remote})
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return plumbing.ZeroHash, err
+	}
+
+	ref, err := repo.Reference(plumbing.NewRemoteReferenceName(s.config.Sync.Remote, head.Name().Short()), true)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return ref.Hash(), nil
+}
+
+// upstreamHash returns where the upstream of the current branch points:
+// the branch it tracks in git config, otherwise the sync remote's branch of
+// the same name. The zero hash means there is no upstream.
+func (s *Service) upstreamHash(repo *git.Repository) plumbing.Hash {
+	branch := branchName(repo)
+	if branch == "" {
+		return plumbing.ZeroHash
+	}
+	if cfg, err := repo.Config(); err == nil {
+		if tracked, ok := cfg.Branches[branch]; ok && tracked.Remote != "" && tracked.Merge != "" {
+			name := plumbing.NewRemoteReferenceName(tracked.Remote, tracked.Merge.Short())
+			if ref, err := repo.Reference(name, true); err == nil {
+				return ref.Hash()
+			}
+		}
+	}
+	return remoteBranchHash(repo, s.config.Sync.Remote)
+}
+
+// clashingFiles lists files changed on both sides since the histories split,
+// counting uncommitted local changes as local
+func clashingFiles(repo *git.Repository, worktree *git.Worktree, remoteHash plumbing.Hash) ([]string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	local, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	remote, err := repo.CommitObject(remoteHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := local.MergeBase(remote)
+	if err != nil {
+		return nil, err
+	}
+	var base *object.Commit
+	if len(bases) > 0 {
+		base = bases[0]
+	}
+
+	localFiles, err := changedSince(base, local)
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	for file, entry := range status {
+		if entry.Staging != git.Unmodified || entry.Worktree != git.Unmodified {
+			localFiles[file] = true
+		}
+	}
+
+	remoteFiles, err := changedSince(base, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for file := range remoteFiles {
+		if localFiles[file] {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// changedSince returns the paths that differ between base and commit. With
+// no common base every file of commit counts as changed.
+func changedSince(base, commit *object.Commit) (map[string]bool, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var baseTree *object.Tree
+	if base != nil {
+		if baseTree, err = base.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(baseTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if change.From.Name != "" {
+			files[change.From.Name] = true
+		}
+		if change.To.Name != "" {
+			files[change.To.Name] = true
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,360 @@
+package timekeeper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/binary"
+	"github.com/go-git/go-git/v5/utils/diff"
+	dmp "github.com/sergi/go-diff/diffmatchpatch"
+
+	"time-machine/internal/models"
+)
+
+// WorkingTreeDiff builds the patch between HEAD and the working tree,
+// covering staged, unstaged and untracked changes
+func (s *Service) WorkingTreeDiff() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// Get worktree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+
+	tree, err := headTree(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetHead), err)}
+	}
+
+	paths := make(map[string]bool, len(status))
+	for path := range status {
+		paths[path] = true
+	}
+
+	patch, err := worktreePatch(worktree.Filesystem.Root(), tree, paths)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	return models.DiffMsg{
+		Title:       models.T(models.TitleWorkingTreeDiff),
+		Patch:       patch,
+		WorkingTree: true,
+	}
+}
+
+// DiffWorkingTreeAgainst builds the patch between the checkpoint hash and
+// the working tree, i.e. everything done since that checkpoint whether
+// saved or not
+func (s *Service) DiffWorkingTreeAgainst(hash string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// Get worktree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	current, err := headTree(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetHead), err)}
+	}
+
+	// Files changed since the checkpoint are the ones committed after it
+	// plus whatever isn't committed yet
+	paths := make(map[string]bool, len(status))
+	for path := range status {
+		paths[path] = true
+	}
+	changes, err := object.DiffTree(tree, current)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths[change.From.Name] = true
+		}
+		if change.To.Name != "" {
+			paths[change.To.Name] = true
+		}
+	}
+
+	patch, err := worktreePatch(worktree.Filesystem.Root(), tree, paths)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	return models.DiffMsg{
+		Title: fmt.Sprintf(models.T(models.TitleWorkingTreeSince), hash, firstLine(commit.Message)),
+		Patch: patch,
+	}
+}
+
+// worktreePatch renders the patch between tree and the working tree for
+// the given paths
+func worktreePatch(root string, tree *object.Tree, paths map[string]bool) (string, error) {
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var filePatches []fdiff.FilePatch
+	for _, path := range sorted {
+		from, err := treeSide(tree, path)
+		if err != nil {
+			return "", err
+		}
+
+		to, err := worktreeSide(root, path)
+		if err != nil {
+			return "", err
+		}
+
+		if fp := buildFilePatch(from, to); fp != nil {
+			filePatches = append(filePatches, fp)
+		}
+	}
+
+	return encodePatch(filePatches)
+}
+
+// headTree returns the tree of the HEAD commit, or nil for a repository without commits
+func headTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// diffSide is one version of a file taking part in a patch
+type diffSide struct {
+	path    string
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	content []byte
+}
+
+func (d *diffSide) Hash() plumbing.Hash     { return d.hash }
+func (d *diffSide) Mode() filemode.FileMode { return d.mode }
+func (d *diffSide) Path() string            { return d.path }
+
+// treeSide reads a file from a tree, returning nil when it doesn't exist there
+func treeSide(tree *object.Tree, path string) (*diffSide, error) {
+	if tree == nil {
+		return nil, nil
+	}
+
+	file, err := tree.File(path)
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diffSide{path: path, hash: file.Hash, mode: file.Mode, content: content}, nil
+}
+
+// worktreeSide reads a file from the working tree, returning nil when it was deleted
+func worktreeSide(root, path string) (*diffSide, error) {
+	fullPath := filepath.Join(root, path)
+	info, err := os.Lstat(fullPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	side := &diffSide{path: path, mode: filemode.Regular}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		side.mode = filemode.Symlink
+		side.content = []byte(target)
+	case info.IsDir():
+		// Submodules and nested repositories show up as directories
+		return nil, nil
+	default:
+		if info.Mode()&0o111 != 0 {
+			side.mode = filemode.Executable
+		}
+		if side.content, err = os.ReadFile(fullPath); err != nil {
+			return nil, err
+		}
+	}
+
+	side.hash = plumbing.ComputeHash(plumbing.BlobObject, side.content)
+	return side, nil
+}
+
+// filePatch is an fdiff.FilePatch between two diff sides
+type filePatch struct {
+	from, to *diffSide
+	chunks   []fdiff.Chunk
+	binary   bool
+}
+
+func (p *filePatch) IsBinary() bool        { return p.binary }
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+func (p *filePatch) Files() (from, to fdiff.File) {
+	// Keep typed nils out of the interfaces
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+// textChunk is a single fdiff.Chunk of a text patch
+type textChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *textChunk) Content() string       { return c.content }
+func (c *textChunk) Type() fdiff.Operation { return c.op }
+
+// patch is an fdiff.Patch made of file patches
+type patch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p *patch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *patch) Message() string                { return "" }
+
+// buildFilePatch computes the patch between two sides, nil when they are identical
+func buildFilePatch(from, to *diffSide) fdiff.FilePatch {
+	if from == nil && to == nil {
+		return nil
+	}
+	if from != nil && to != nil && from.hash == to.hash && from.mode == to.mode {
+		return nil
+	}
+
+	fp := &filePatch{from: from, to: to}
+	if isBinarySide(from) || isBinarySide(to) {
+		fp.binary = true
+		return fp
+	}
+
+	var fromContent, toContent string
+	if from != nil {
+		fromContent = string(from.content)
+	}
+	if to != nil {
+		toContent = string(to.content)
+	}
+
+	for _, d := range diff.Do(fromContent, toContent) {
+		var op fdiff.Operation
+		switch d.Type {
+		case dmp.DiffEqual:
+			op = fdiff.Equal
+		case dmp.DiffDelete:
+			op = fdiff.Delete
+		case dmp.DiffInsert:
+			op = fdiff.Add
+		}
+		fp.chunks = append(fp.chunks, &textChunk{content: d.Text, op: op})
+	}
+
+	return fp
+}
+
+// isBinarySide reports whether the side holds binary content
+func isBinarySide(side *diffSide) bool {
+	if side == nil {
+		return false
+	}
+	isBinary, err := binary.IsBinary(bytes.NewReader(side.content))
+	return err == nil && isBinary
+}
+
+// encodePatch renders file patches in unified diff format
+func encodePatch(filePatches []fdiff.FilePatch) (string, error) {
+	var buf bytes.Buffer
+	encoder := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines)
+	if err := encoder.Encode(&patch{filePatches: filePatches}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,103 @@
+package timekeeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"time-machine/internal/models"
+)
+
+// FriendlyError is a go-git error explained in the UI language
+type FriendlyError struct {
+	Category string
+	Text     string
+	Err      error
+}
+
+func (e *FriendlyError) Error() string {
+	return fmt.Sprintf("%s: %s", models.T(e.Category), e.Text)
+}
+
+func (e *FriendlyError) Unwrap() error {
+	return e.Err
+}
+
+// knownErrors maps go-git sentinels to their category and explanation
+var knownErrors = []struct {
+	err      error
+	category string
+	hint     string
+}{
+	{transport.ErrAuthenticationRequired, models.ErrCategoryAuth, models.ErrHintAuthRequired},
+	{transport.ErrAuthorizationFailed, models.ErrCategoryAuth, models.ErrHintAuthFailed},
+	{transport.ErrInvalidAuthMethod, models.ErrCategoryAuth, models.ErrHintAuthRequired},
+	{transport.ErrRepositoryNotFound, models.ErrCategoryNetwork, models.ErrHintRemoteNotFound},
+	{transport.ErrEmptyRemoteRepository, models.ErrCategoryNetwork, models.ErrHintRemoteEmpty},
+	{context.DeadlineExceeded, models.ErrCategoryNetwork, models.ErrHintTimeout},
+	{git.ErrRepositoryNotExists, models.ErrCategoryRepo, models.ErrHintNotRepo},
+	{git.ErrRemoteNotFound, models.ErrCategoryRepo, models.ErrHintNoRemote},
+	{plumbing.ErrReferenceNotFound, models.ErrCategoryRepo, models.ErrHintRefNotFound},
+	{git.ErrBranchNotFound, models.ErrCategoryRepo, models.ErrHintRefNotFound},
+	{plumbing.ErrObjectNotFound, models.ErrCategoryRepo, models.ErrHintObjectMissing},
+	{git.ErrNonFastForwardUpdate, models.ErrCategoryRepo, models.ErrHintNonFastForward},
+	{git.ErrForceNeeded, models.ErrCategoryRepo, models.ErrHintNonFastForward},
+	{git.ErrWorktreeNotClean, models.ErrCategoryRepo, models.ErrHintDirtyWorktree},
+	{git.ErrUnstagedChanges, models.ErrCategoryRepo, models.ErrHintDirtyWorktree},
+	{git.ErrEmptyCommit, models.ErrCategoryRepo, models.ErrHintEmptyCommit},
+}
+
+// Explain turns common go-git errors into a FriendlyError. The context our
+// code wrapped around the sentinel ("не удалось отправить копию: ...") is
+// kept; unknown errors are returned untouched.
+func Explain(err error) error {
+	if err == nil {
+		return nil
+	}
+	var friendly *FriendlyError
+	if errors.As(err, &friendly) {
+		return err
+	}
+
+	for _, known := range knownErrors {
+		if errors.Is(err, known.err) {
+			return explained(err, known.err.Error(), known.category, known.hint)
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		hint := models.ErrHintUnreachable
+		if netErr.Timeout() {
+			hint = models.ErrHintTimeout
+		}
+		return explained(err, netErr.Error(), models.ErrCategoryNetwork, hint)
+	}
+	return err
+}
+
+// isConnectionError reports whether err is a network or auth failure rather
+// than a problem with the history itself
+func isConnectionError(err error) bool {
+	var friendly *FriendlyError
+	if !errors.As(Explain(err), &friendly) {
+		return false
+	}
+	return friendly.Category == models.ErrCategoryNetwork || friendly.Category == models.ErrCategoryAuth
+}
+
+// explained builds a FriendlyError keeping the prefix that preceded the raw
+// go-git message
+func explained(err error, raw, category, hint string) error {
+	text := models.T(hint)
+	if prefix, found := strings.CutSuffix(err.Error(), raw); found && prefix != "" {
+		text = prefix + text
+	}
+	return &FriendlyError{Category: category, Text: text, Err: err}
+}
@@ -0,0 +1,95 @@
+package timekeeper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+
+	"time-machine/internal/models"
+)
+
+// hookTimeout stops a hook that hangs, it must not hold the app hostage
+const hookTimeout = 30 * time.Second
+
+// Environment variables describing the event to a hook
+const (
+	envHookEvent  = "VIBEGIT_EVENT"
+	envHookRepo   = "VIBEGIT_REPO"
+	envHookBranch = "VIBEGIT_BRANCH"
+	envHookHash   = "VIBEGIT_HASH"
+)
+
+// RunHooks runs the commands configured for event one by one in the
+// repository directory, with the branch and HEAD hash in the environment.
+// Returns nil when no hooks are configured.
+func (s *Service) RunHooks(event string) tea.Msg {
+	commands := s.config.Hooks.Commands(event)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Hooks run from the repository root whatever subdirectory the app is in
+	root, branch, hash := pwd, "", ""
+	if repo, err := s.openRepository(pwd); err == nil {
+		defer s.releaseRepository(repo)
+		if worktree, err := repo.Worktree(); err == nil {
+			root = worktree.Filesystem.Root()
+		}
+		branch = branchName(repo)
+		if head := headHash(repo); !head.IsZero() {
+			hash = head.String()
+		}
+	}
+	env := append(os.Environ(),
+		envHookEvent+"="+event,
+		envHookRepo+"="+root,
+		envHookBranch+"="+branch,
+		envHookHash+"="+hash,
+	)
+
+	msg := models.HooksMsg{Event: event, ShowOutput: s.config.Hooks.ShowOutput}
+	for _, command := range commands {
+		result := models.HookResult{Command: command}
+		output, err := runHook(command, root, env)
+		result.Output = strings.TrimSpace(string(output))
+		if err != nil {
+			result.Err = err.Error()
+		}
+		msg.Results = append(msg.Results, result)
+	}
+	return msg
+}
+
+// runHook runs command through the platform shell and returns everything it
+// printed
+func runHook(command, dir string, env []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = env
+
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, errors.New(models.T(models.ErrHookTimeout))
+	}
+	return output, err
+}
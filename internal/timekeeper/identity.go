@@ -0,0 +1,222 @@
+package timekeeper
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// userSignature returns the identity configured in git (the repository
+// config overrides the global one), falling back to the given name and
+// email for whatever is not set
+func userSignature(repo *git.Repository, fallbackName, fallbackEmail string) *object.Signature {
+	signature := &object.Signature{
+		Name:  fallbackName,
+		Email: fallbackEmail,
+		When:  time.Now(),
+	}
+
+	cfg, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return signature
+	}
+
+	if cfg.User.Name != "" {
+		signature.Name = cfg.User.Name
+	}
+	if cfg.User.Email != "" {
+		signature.Email = cfg.User.Email
+	}
+	return signature
+}
+
+// signature returns userSignature with the author from the config, if any,
+// taking priority over git's
+func (s *Service) signature(repo *git.Repository, fallbackName, fallbackEmail string) *object.Signature {
+	signature := userSignature(repo, fallbackName, fallbackEmail)
+	signature.When = s.now()
+	if s.config.Author.Name != "" {
+		signature.Name = s.config.Author.Name
+	}
+	if s.config.Author.Email != "" {
+		signature.Email = s.config.Author.Email
+	}
+	return signature
+}
+
+// validateEmail accepts a bare address like "me@example.com", without a
+// display name or angle brackets
+func validateEmail(email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return fmt.Errorf("%s: %q", models.T(models.ErrInvalidAuthorEmail), email)
+	}
+	return nil
+}
+
+// identityMissing reports whether neither git nor the config says who the
+// user is, so their commits would go out under a placeholder name
+func (s *Service) identityMissing(repo *git.Repository) bool {
+	if s.config.Author.Name != "" && s.config.Author.Email != "" {
+		return false
+	}
+	cfg, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return false
+	}
+	name := cfg.User.Name != "" || s.config.Author.Name != ""
+	email := cfg.User.Email != "" || s.config.Author.Email != ""
+	return !name || !email
+}
+
+// SetIdentity stores user.name and user.email in the global git config,
+// like `git config --global`. Only those two entries are written, the rest
+// of the file, comments included, is kept as is.
+func (s *Service) SetIdentity(name, email string) tea.Msg {
+	if name == "" {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %s", models.T(models.ErrFailedToSetIdentity), models.T(models.ErrEmptyIdentityName))}
+	}
+	if err := validateEmail(email); err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	path, err := globalConfigPath()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSetIdentity), err)}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSetIdentity), err)}
+	}
+	data = setUserEntries(data, map[string]string{"name": name, "email": email})
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSetIdentity), err)}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSetIdentity), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextIdentitySet), name, email)}
+}
+
+// setUserEntries writes entries into the [user] section of the git config
+// in data, editing the lines in place. An entry that appears again further
+// on is dropped, a missing one goes right under the first [user] header,
+// and a file without one gets a [user] section at the end. Decoding and
+// encoding the whole file would lose its comments.
+func setUserEntries(data []byte, entries map[string]string) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	var out []string
+	header := -1
+	written := map[string]bool{}
+	inUser := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inUser = isUserHeader(trimmed)
+			if inUser && header < 0 {
+				header = len(out)
+			}
+			out = append(out, line)
+			continue
+		}
+		key := configKey(trimmed)
+		if value, ok := entries[key]; ok && inUser {
+			if !written[key] {
+				indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				out = append(out, indent+key+" = "+quoteConfigValue(value)+"\n")
+				written[key] = true
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+
+	// Missing entries keep the order they are usually written in
+	var missing []string
+	for _, key := range []string{"name", "email"} {
+		if value, ok := entries[key]; ok && !written[key] {
+			missing = append(missing, "\t"+key+" = "+quoteConfigValue(value)+"\n")
+		}
+	}
+	if len(missing) > 0 {
+		if header < 0 {
+			if n := len(out); n > 0 && out[n-1] != "" && !strings.HasSuffix(out[n-1], "\n") {
+				out[n-1] += "\n"
+			}
+			out = append(out, "[user]\n")
+			header = len(out) - 1
+		}
+		if !strings.HasSuffix(out[header], "\n") {
+			out[header] += "\n"
+		}
+		out = append(out[:header+1], append(missing, out[header+1:]...)...)
+	}
+	return []byte(strings.Join(out, ""))
+}
+
+// isUserHeader reports whether the section header opens [user], not one of
+// its subsections
+func isUserHeader(header string) bool {
+	end := strings.Index(header, "]")
+	if end < 0 {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(header[1:end]), "user")
+}
+
+// configKey returns the lowercased key of a git config entry line, empty
+// for comments and blank lines
+func configKey(line string) string {
+	if line == "" || line[0] == '#' || line[0] == ';' {
+		return ""
+	}
+	key := line
+	if i := strings.IndexAny(key, "= \t"); i >= 0 {
+		key = key[:i]
+	}
+	return strings.ToLower(key)
+}
+
+// quoteConfigValue quotes value for a git config file when it has spaces at
+// the ends or characters that would start a comment, escaping quotes and
+// backslashes
+func quoteConfigValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	if escaped != value || strings.ContainsAny(value, "#;") || strings.TrimSpace(value) != value {
+		return `"` + escaped + `"`
+	}
+	return value
+}
+
+// globalConfigPath returns the global git config file git itself would
+// use: the first one that exists, ~/.gitconfig when there is none yet
+func globalConfigPath() (string, error) {
+	paths, err := gitconfig.Paths(gitconfig.GlobalScope)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".gitconfig"), nil
+}
@@ -0,0 +1,222 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	"time-machine/internal/models"
+)
+
+// isolateGitConfig points the global git config at an empty home, so the
+// identity of whoever runs the tests can't leak in
+func isolateGitConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return home
+}
+
+// setRepoIdentity writes user.name and user.email to the config of the
+// repository in dir
+func setRepoIdentity(t *testing.T, dir, name, email string) *git.Repository {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name, cfg.User.Email = name, email
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// headCommitOf returns the author and committer, name and email, of the
+// commit HEAD points to in dir
+func headCommitOf(t *testing.T, dir string) (author, committer [2]string) {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return [2]string{commit.Author.Name, commit.Author.Email}, [2]string{commit.Committer.Name, commit.Committer.Email}
+}
+
+func TestUserSignature(t *testing.T) {
+	home := isolateGitConfig(t)
+	dir := newTestRepo(t, 1, 1)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := userSignature(repo, "Fallback", "fallback@example.com"); got.Name != "Fallback" || got.Email != "fallback@example.com" {
+		t.Errorf("without any git identity got %s <%s>, want the fallback", got.Name, got.Email)
+	}
+
+	global := "[user]\n\tname = Global User\n\temail = global@example.com\n"
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(global), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := userSignature(repo, "Fallback", "fallback@example.com"); got.Name != "Global User" || got.Email != "global@example.com" {
+		t.Errorf("with a global identity got %s <%s>", got.Name, got.Email)
+	}
+
+	repo = setRepoIdentity(t, dir, "Repo User", "repo@example.com")
+	if got := userSignature(repo, "Fallback", "fallback@example.com"); got.Name != "Repo User" || got.Email != "repo@example.com" {
+		t.Errorf("the repository identity doesn't override the global one, got %s <%s>", got.Name, got.Email)
+	}
+}
+
+func TestCreateCheckpointIdentity(t *testing.T) {
+	tool := [2]string{models.CheckpointAuthorName, models.CheckpointAuthorEmail}
+
+	tests := []struct {
+		name          string
+		configAuthor  [2]string
+		opts          CheckpointOptions
+		wantAuthor    [2]string
+		wantCommitter [2]string
+	}{
+		{
+			name:          "git config author, tool committer",
+			wantAuthor:    [2]string{"Repo User", "repo@example.com"},
+			wantCommitter: tool,
+		},
+		{
+			name:          "vibegit config overrides git config",
+			configAuthor:  [2]string{"Config User", "config@example.com"},
+			wantAuthor:    [2]string{"Config User", "config@example.com"},
+			wantCommitter: tool,
+		},
+		{
+			name:          "author name for one checkpoint",
+			opts:          CheckpointOptions{AuthorName: "Pair"},
+			wantAuthor:    [2]string{"Pair", "repo@example.com"},
+			wantCommitter: tool,
+		},
+		{
+			name:          "another email commits as that author",
+			opts:          CheckpointOptions{AuthorName: "Pair", AuthorEmail: "pair@example.com"},
+			wantAuthor:    [2]string{"Pair", "pair@example.com"},
+			wantCommitter: [2]string{"Pair", "pair@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateGitConfig(t)
+			dir := newTestRepo(t, 1, 1)
+			setRepoIdentity(t, dir, "Repo User", "repo@example.com")
+			s := newTestService(t, dir)
+			s.config.Author.Name, s.config.Author.Email = tt.configAuthor[0], tt.configAuthor[1]
+
+			if err := os.WriteFile(filepath.Join(dir, "change.txt"), []byte("change\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts := tt.opts
+			opts.Confirmed = true
+			if msg, ok := s.CreateCheckpoint("identity", opts).(models.CheckpointCreatedMsg); !ok || !msg.Success {
+				t.Fatalf("CreateCheckpoint failed: %#v", msg)
+			}
+
+			author, committer := headCommitOf(t, dir)
+			if author != tt.wantAuthor {
+				t.Errorf("author = %v, want %v", author, tt.wantAuthor)
+			}
+			if committer != tt.wantCommitter {
+				t.Errorf("committer = %v, want %v", committer, tt.wantCommitter)
+			}
+		})
+	}
+}
+
+func TestSetIdentityKeepsGlobalConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "user section",
+			file: "# my settings\n[core]\n\teditor = vim ; the only one\n[user]\n\t# who I am\n\tname = Old Name\n\temail = old@example.com\n\tsigningkey = ABC\n[alias \"x\"]\n\tst = status\n",
+			want: "# my settings\n[core]\n\teditor = vim ; the only one\n[user]\n\t# who I am\n\tname = New Name\n\temail = new@example.com\n\tsigningkey = ABC\n[alias \"x\"]\n\tst = status\n",
+		},
+		{
+			name: "user section without an email",
+			file: "[user]\n    name = Old Name\n; trailing comment\n",
+			want: "[user]\n\temail = new@example.com\n    name = New Name\n; trailing comment\n",
+		},
+		{
+			name: "no user section",
+			file: "# just a comment\n[core]\n\tautocrlf = input",
+			want: "# just a comment\n[core]\n\tautocrlf = input\n[user]\n\tname = New Name\n\temail = new@example.com\n",
+		},
+		{
+			name: "no file",
+			want: "[user]\n\tname = New Name\n\temail = new@example.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolateGitConfig(t)
+			path := filepath.Join(home, ".gitconfig")
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s := newTestService(t, newTestRepo(t, 1, 1))
+
+			if _, ok := s.SetIdentity("New Name", "new@example.com").(models.StatusMsg); !ok {
+				t.Fatal("SetIdentity failed")
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("global config =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			// And git reads it back
+			repo, err := git.PlainOpen(s.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := userSignature(repo, "", ""); got.Name != "New Name" || got.Email != "new@example.com" {
+				t.Errorf("git reads %s <%s>", got.Name, got.Email)
+			}
+		})
+	}
+}
+
+func TestQuoteConfigValue(t *testing.T) {
+	for value, want := range map[string]string{
+		"Plain Name":   "Plain Name",
+		"Team #1":      `"Team #1"`,
+		` padded `:     `" padded "`,
+		`Nick "N" Doe`: `"Nick \"N\" Doe"`,
+	} {
+		if got := quoteConfigValue(value); got != want {
+			t.Errorf("quoteConfigValue(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+)
+
+// statusSkippingLarge computes the worktree status without hashing tracked
+// files bigger than limit bytes: go-git reads every file in full to compare
+// it with the index, which takes ages for multi-gigabyte files. Those files
+// are judged by size and modification time instead, like git's stat cache,
+// and the changed ones are returned separately. A limit of 0 hashes everything.
+func statusSkippingLarge(repo *git.Repository, worktree *git.Worktree, limit int64) (git.Status, []string, error) {
+	if limit <= 0 {
+		status, err := worktree.Status()
+		return status, nil, err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := worktree.Filesystem.Root()
+	hidden := make(map[string]bool)
+	var changed []string
+	for _, entry := range idx.Entries {
+		info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(entry.Name)))
+		if err != nil || !info.Mode().IsRegular() || info.Size() <= limit {
+			continue
+		}
+		hidden[entry.Name] = true
+		// The index keeps the size truncated to 32 bits
+		if uint32(info.Size()) != entry.Size || !info.ModTime().Equal(entry.ModifiedAt) {
+			changed = append(changed, entry.Name)
+		}
+	}
+
+	if len(hidden) == 0 {
+		status, err := worktree.Status()
+		return status, nil, err
+	}
+
+	// Hidden files look deleted to go-git, their worktree state is set
+	// from the stat comparison afterwards
+	original := worktree.Filesystem
+	worktree.Filesystem = &hidingFilesystem{Filesystem: original, hidden: hidden}
+	status, err := worktree.Status()
+	worktree.Filesystem = original
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isChanged := make(map[string]bool, len(changed))
+	for _, path := range changed {
+		isChanged[path] = true
+	}
+	for path := range hidden {
+		entry := status.File(path)
+		entry.Worktree = git.Unmodified
+		if isChanged[path] {
+			entry.Worktree = git.Modified
+		}
+		if entry.Staging == git.Unmodified && entry.Worktree == git.Unmodified {
+			delete(status, path)
+		}
+	}
+
+	return status, changed, nil
+}
+
+// hidingFilesystem leaves the hidden paths out of directory listings
+type hidingFilesystem struct {
+	billy.Filesystem
+	hidden map[string]bool
+}
+
+func (fs *hidingFilesystem) ReadDir(path string) ([]os.FileInfo, error) {
+	infos, err := fs.Filesystem.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := infos[:0]
+	for _, info := range infos {
+		if !fs.hidden[filepath.ToSlash(filepath.Join(path, info.Name()))] {
+			visible = append(visible, info)
+		}
+	}
+	return visible, nil
+}
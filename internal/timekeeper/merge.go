@@ -0,0 +1,349 @@
+package timekeeper
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// Files git keeps in .git while a merge waits to be committed
+const (
+	mergeHeadFile = "MERGE_HEAD"
+	mergeMsgFile  = "MERGE_MSG"
+)
+
+// mergeStateFiles are removed once the merge is committed or aborted
+var mergeStateFiles = []string{mergeHeadFile, mergeMsgFile, "MERGE_MODE", "AUTO_MERGE"}
+
+// mergeHeads returns the commits being merged into HEAD, nil when no merge
+// is in progress
+func mergeHeads(repo *git.Repository) ([]plumbing.Hash, error) {
+	fs, err := dotGit(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fs.Open(mergeHeadFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var heads []plumbing.Hash
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); plumbing.IsHash(line) {
+			heads = append(heads, plumbing.NewHash(line))
+		}
+	}
+	return heads, scanner.Err()
+}
+
+// MergeContinue commits the resolved merge with HEAD and the merged
+// commits as parents, like `git merge --continue`. It refuses while files
+// still hold conflict markers.
+func (s *Service) MergeContinue() (msg tea.Msg) {
+	defer func() { s.record(models.ActivityMerge, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	heads, err := mergeHeads(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToMerge), err)}
+	}
+	if len(heads) == 0 {
+		return models.ErrMsg{Error: errors.New(models.T(models.ErrNoMergeInProgress))}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetHead), err)}
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	unresolved, err := filesWithConflictMarkers(worktree)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToMerge), err)}
+	}
+	if len(unresolved) > 0 {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrMergeUnresolved), strings.Join(unresolved, ", "))}
+	}
+
+	// The resolutions are whatever the files hold now
+	conflicted, err := dropConflictEntries(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToAddChanges), err)}
+	}
+	if err := stageResolution(worktree, conflicted, s.config.Checkpoint.IncludeUntracked); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToAddChanges), err)}
+	}
+
+	fs, err := dotGit(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToMerge), err)}
+	}
+
+	author := s.signature(repo, models.CheckpointAuthorName, models.CheckpointAuthorEmail)
+	commit, err := worktree.Commit(mergeMessage(fs), &git.CommitOptions{
+		Author:            author,
+		Parents:           append([]plumbing.Hash{head.Hash()}, heads...),
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToMerge), err)}
+	}
+
+	if err := clearMergeState(fs); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToMerge), err)}
+	}
+
+	return models.StatusMsg{
+		Text:    fmt.Sprintf(models.T(models.TextMergeCommitted), commit.String()),
+		Summary: summarize(repo, models.T(models.TitleSummaryMerge), head.Hash(), commit),
+	}
+}
+
+// MergeAbort throws the merge away and puts the files back as they were in
+// HEAD, like `git merge --abort`
+func (s *Service) MergeAbort() (msg tea.Msg) {
+	defer func() { s.record(models.ActivityMerge, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	heads, err := mergeHeads(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToAbortMerge), err)}
+	}
+	if len(heads) == 0 {
+		return models.ErrMsg{Error: errors.New(models.T(models.ErrNoMergeInProgress))}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetHead), err)}
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	if err := restoreTracked(repo, worktree, head.Hash()); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToAbortMerge), err)}
+	}
+
+	fs, err := dotGit(repo)
+	if err == nil {
+		err = clearMergeState(fs)
+	}
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToAbortMerge), err)}
+	}
+
+	return models.StatusMsg{Text: models.T(models.TextMergeAborted)}
+}
+
+// restoreTracked puts every changed file git knows about back as it is in
+// the commit hash and matches the index, like `git merge --abort`. Untracked
+// files are neither in the index nor in the commit and stay where they are,
+// go-git's hard reset would delete them.
+func restoreTracked(repo *git.Repository, worktree *git.Worktree, hash plumbing.Hash) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	tree, err := commitTree(repo, hash)
+	if err != nil {
+		return err
+	}
+
+	var files []stashFile
+	for name, entry := range status {
+		if entry.Worktree == git.Untracked || (entry.Staging == git.Unmodified && entry.Worktree == git.Unmodified) {
+			continue
+		}
+		// Files the merge added aren't in the commit and get deleted
+		file, err := tree.File(name)
+		if err != nil && !errors.Is(err, object.ErrFileNotFound) {
+			return err
+		}
+		files = append(files, stashFile{path: name, file: file})
+	}
+	if err := writeFiles(worktree.Filesystem.Root(), files); err != nil {
+		return err
+	}
+	return worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset})
+}
+
+// dropConflictEntries removes the base, ours and theirs versions git keeps
+// in the index for conflicted files and returns those files. go-git's Add
+// updates one of them in place instead of replacing all three with the
+// resolved file.
+func dropConflictEntries(repo *git.Repository) (map[string]bool, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolved entries have stage 0, go-git's index.Merged constant is 1
+	// and can't be used to tell them apart
+	conflicted := map[string]bool{}
+	entries := idx.Entries[:0]
+	for _, entry := range idx.Entries {
+		if entry.Stage == 0 {
+			entries = append(entries, entry)
+		} else {
+			conflicted[entry.Name] = true
+		}
+	}
+	idx.Entries = entries
+	return conflicted, repo.Storer.SetIndex(idx)
+}
+
+// stageResolution stages the resolved conflicted files and the changes to
+// tracked ones. New files only go in when checkpoints take them too.
+func stageResolution(worktree *git.Worktree, conflicted map[string]bool, includeUntracked bool) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for file, entry := range status {
+		switch {
+		case conflicted[file]:
+			if entry.Worktree != git.Unmodified {
+				paths = append(paths, file)
+			}
+		case entry.Worktree == git.Untracked:
+			if includeUntracked {
+				paths = append(paths, file)
+			}
+		case entry.Worktree != git.Unmodified:
+			paths = append(paths, file)
+		}
+	}
+	return stagePaths(worktree, paths)
+}
+
+// filesWithConflictMarkers lists the changed files still holding the
+// <<<<<<< and >>>>>>> lines git leaves around conflicts
+func filesWithConflictMarkers(worktree *git.Worktree) ([]string, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for file, entry := range status {
+		if entry.Worktree == git.Deleted || (entry.Staging == git.Unmodified && entry.Worktree == git.Unmodified) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(worktree.Filesystem.Root(), file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if hasConflictMarkers(content) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// hasConflictMarkers reports whether content has a line opening and a line
+// closing a conflict
+func hasConflictMarkers(content []byte) bool {
+	var opened bool
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		switch {
+		case bytes.HasPrefix(line, []byte("<<<<<<< ")):
+			opened = true
+		case opened && bytes.HasPrefix(line, []byte(">>>>>>> ")):
+			return true
+		}
+	}
+	return false
+}
+
+// mergeMessage returns the message git prepared for the merge commit
+// without its comment lines, or the default one
+func mergeMessage(fs billy.Filesystem) string {
+	file, err := fs.Open(mergeMsgFile)
+	if err != nil {
+		return models.T(models.MergeCommitMessage)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return models.T(models.MergeCommitMessage)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if message := strings.TrimSpace(strings.Join(lines, "\n")); message != "" {
+		return message
+	}
+	return models.T(models.MergeCommitMessage)
+}
+
+// clearMergeState removes the files marking a merge in progress
+func clearMergeState(fs billy.Filesystem) error {
+	for _, name := range mergeStateFiles {
+		if err := fs.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+
+	"time-machine/internal/models"
+)
+
+func TestMergeContinueLeavesNewFilesOut(t *testing.T) {
+	isolateGitConfig(t)
+	dir := newTestRepo(t, 2, 1)
+	s := newTestService(t, dir)
+	s.config.Checkpoint.IncludeUntracked = false
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := commit.Parent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := commit.File("file000.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A merge of the parent stopped on both.txt, added on both sides
+	if err := os.WriteFile(filepath.Join(dir, ".git", mergeHeadFile), []byte(other.Hash.String()+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stage := range []index.Stage{index.OurMode, index.TheirMode} {
+		idx.Entries = append(idx.Entries, &index.Entry{Name: "both.txt", Hash: file.Hash, Mode: file.Mode, Stage: stage})
+	}
+	sort.Slice(idx.Entries, func(i, j int) bool { return idx.Entries[i].Name < idx.Entries[j].Name })
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{
+		"both.txt":    "resolved\n",
+		"file000.txt": "edited\n",
+		"scratch.txt": "not for the merge\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if msg, ok := s.MergeContinue().(models.StatusMsg); !ok {
+		t.Fatalf("merge continue = %#v", msg)
+	}
+
+	merged, err := repo.CommitObject(headHash(repo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.NumParents() != 2 {
+		t.Errorf("merge commit has %d parents", merged.NumParents())
+	}
+	for name, want := range map[string]string{"both.txt": "resolved\n", "file000.txt": "edited\n"} {
+		file, err := merged.File(name)
+		if err != nil {
+			t.Errorf("%s is not in the merge: %v", name, err)
+			continue
+		}
+		if got, _ := file.Contents(); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := merged.File("scratch.txt"); err == nil {
+		t.Error("the untracked scratch.txt went into the merge")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scratch.txt")); err != nil {
+		t.Errorf("scratch.txt is gone: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package timekeeper
+
+import (
+	"strings"
+
+	"time-machine/internal/models"
+)
+
+// DefaultMessage returns the description used when none was typed: the
+// configured template with {date}, {time} and {branch} filled in, or the
+// built-in text when no template is set
+func (s *Service) DefaultMessage() string {
+	template := s.config.Checkpoint.DefaultMessage
+	if template == "" {
+		return models.T(models.DefaultCheckpointMessage)
+	}
+
+	branch := ""
+	if pwd, err := s.workDir(); err == nil {
+		if repo, err := s.openRepository(pwd); err == nil {
+			branch = branchName(repo)
+			s.releaseRepository(repo)
+		}
+	}
+
+	now := s.now()
+	return strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("15:04"),
+		"{branch}", branch,
+	).Replace(template)
+}
+
+// Suggestions returns the moods offered for the description, the configured
+// ones or the built-in list when none are set
+func (s *Service) Suggestions() []string {
+	if len(s.config.Checkpoint.Suggestions) == 0 {
+		suggestions := make([]string, len(models.DefaultSuggestions))
+		for i, suggestion := range models.DefaultSuggestions {
+			suggestions[i] = models.T(suggestion)
+		}
+		return suggestions
+	}
+	return s.config.Checkpoint.Suggestions
+}
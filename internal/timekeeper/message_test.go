@@ -0,0 +1,82 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"time-machine/internal/config"
+	"time-machine/internal/models"
+)
+
+func TestSuggestionsFromConfig(t *testing.T) {
+	builtIn := make([]string, len(models.DefaultSuggestions))
+	for i, suggestion := range models.DefaultSuggestions {
+		builtIn[i] = models.T(suggestion)
+	}
+
+	tests := []struct {
+		name    string
+		file    string // the repository's .vibegit.json, empty for none
+		wantErr bool
+		want    []string
+	}{
+		{
+			name: "missing file",
+			want: builtIn,
+		},
+		{
+			name: "no suggestions key",
+			file: `{"checkpoint": {"autoMinutes": 5}}`,
+			want: builtIn,
+		},
+		{
+			name: "empty list",
+			file: `{"checkpoint": {"suggestions": []}}`,
+			want: builtIn,
+		},
+		{
+			name: "own list",
+			file: `{"checkpoint": {"suggestions": ["Ревью", "Хотфикс"]}}`,
+			want: []string{"Ревью", "Хотфикс"},
+		},
+		{
+			name:    "malformed file",
+			file:    `{"checkpoint": {"suggestions": ["Ревью",`,
+			wantErr: true,
+			want:    builtIn,
+		},
+		{
+			name:    "suggestions of the wrong type",
+			file:    `{"checkpoint": {"suggestions": "Ревью"}}`,
+			wantErr: true,
+			want:    builtIn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No global config either
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+			dir := t.TempDir()
+			if tt.file != "" {
+				if err := os.WriteFile(filepath.Join(dir, config.RepoFileName), []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Like on start, a config that can't be read leaves the defaults
+			cfg, err := config.Load(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := NewService(cfg).Suggestions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggestions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// notesRef is where git keeps notes by default, `git notes` and `git log`
+// read them from there too
+const notesRef = plumbing.ReferenceName("refs/notes/commits")
+
+// AddNote attaches note to the checkpoint hash without rewriting it,
+// replacing the note it had. An empty note removes it.
+func (s *Service) AddNote(hash, note string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSaveNote), err)}
+	}
+
+	note = strings.TrimSpace(note)
+	if err := s.writeNote(repo, commit.Hash, note); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSaveNote), err)}
+	}
+
+	return models.NoteMsg{Hash: commit.Hash.String(), Note: note}
+}
+
+// GetNote reads the note attached to the checkpoint hash, empty when there
+// is none
+func (s *Service) GetNote(hash string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	notes, err := readNotes(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToReadNotes), err)}
+	}
+
+	return models.NoteMsg{Hash: hash, Note: notes[plumbing.NewHash(hash)]}
+}
+
+// noteBlobs maps every annotated object to the blob of its note, along with
+// the current notes commit, nil when there are no notes yet
+func noteBlobs(repo *git.Repository) (map[plumbing.Hash]plumbing.Hash, *object.Commit, error) {
+	blobs := make(map[plumbing.Hash]plumbing.Hash)
+
+	ref, err := repo.Reference(notesRef, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return blobs, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Big note trees are fanned out into directories like ab/cdef..., the
+	// path without slashes is the annotated hash either way
+	err = tree.Files().ForEach(func(file *object.File) error {
+		name := strings.ReplaceAll(file.Name, "/", "")
+		if len(name) == 40 {
+			blobs[plumbing.NewHash(name)] = file.Hash
+		}
+		return nil
+	})
+	return blobs, commit, err
+}
+
+// readNotes returns the text of every note by the annotated hash
+func readNotes(repo *git.Repository) (map[plumbing.Hash]string, error) {
+	blobs, _, err := noteBlobs(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make(map[plumbing.Hash]string, len(blobs))
+	for target, blobHash := range blobs {
+		blob, err := repo.BlobObject(blobHash)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		notes[target] = strings.TrimSpace(string(content))
+	}
+	return notes, nil
+}
+
+// writeNote records note for target in a new notes commit. The tree is
+// written flat, git reads flat and fanned out trees alike.
+func (s *Service) writeNote(repo *git.Repository, target plumbing.Hash, note string) error {
+	blobs, parent, err := noteBlobs(repo)
+	if err != nil {
+		return err
+	}
+
+	if note == "" {
+		delete(blobs, target)
+	} else {
+		blob := repo.Storer.NewEncodedObject()
+		blob.SetType(plumbing.BlobObject)
+		writer, err := blob.Writer()
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write([]byte(note + "\n")); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		if blobs[target], err = repo.Storer.SetEncodedObject(blob); err != nil {
+			return err
+		}
+	}
+
+	tree := &object.Tree{}
+	for annotated, blobHash := range blobs {
+		tree.Entries = append(tree.Entries, object.TreeEntry{
+			Name: annotated.String(),
+			Mode: filemode.Regular,
+			Hash: blobHash,
+		})
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return tree.Entries[i].Name < tree.Entries[j].Name
+	})
+	treeObject := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(treeObject); err != nil {
+		return err
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(treeObject)
+	if err != nil {
+		return err
+	}
+
+	author := s.signature(repo, models.CheckpointAuthorName, models.CheckpointAuthorEmail)
+	commit := &object.Commit{
+		Author:    *author,
+		Committer: *author,
+		Message:   models.NotesCommitMessage,
+		TreeHash:  treeHash,
+	}
+	if parent != nil {
+		commit.ParentHashes = []plumbing.Hash{parent.Hash}
+	}
+	commitObject := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObject); err != nil {
+		return err
+	}
+	commitHash, err := repo.Storer.SetEncodedObject(commitObject)
+	if err != nil {
+		return err
+	}
+
+	return repo.Storer.SetReference(plumbing.NewHashReference(notesRef, commitHash))
+}
+
+// pushNotes sends the notes to the remote next to the branch
+func (s *Service) pushNotes(repo *git.Repository, remote *git.Remote) error {
+	if _, err := repo.Reference(notesRef, true); err != nil {
+		// Nothing to send
+		return nil
+	}
+
+	spec := gitconfig.RefSpec(notesRef + ":" + notesRef)
+	err := s.withRetry(models.T("Отправляю заметки"), func() error {
+		return remote.Push(&git.PushOptions{
+			RemoteName: s.config.Sync.Remote,
+			RefSpecs:   []gitconfig.RefSpec{spec},
+		})
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
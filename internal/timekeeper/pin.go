@@ -0,0 +1,78 @@
+package timekeeper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// pinRefPrefix holds one local ref per pinned checkpoint. The refs aren't
+// pushed, and they keep the pinned commits alive even when a rollback
+// leaves them off the branch.
+const pinRefPrefix = "refs/vibegit/pins/"
+
+// PinCheckpoint protects the checkpoint hash from being squashed
+func (s *Service) PinCheckpoint(hash string) tea.Msg {
+	return s.setPinned(hash, true)
+}
+
+// UnpinCheckpoint lifts the protection PinCheckpoint put on hash
+func (s *Service) UnpinCheckpoint(hash string) tea.Msg {
+	return s.setPinned(hash, false)
+}
+
+// setPinned creates or removes the pin ref of hash
+func (s *Service) setPinned(hash string, pinned bool) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPin), err)}
+	}
+
+	name := plumbing.ReferenceName(pinRefPrefix + commit.Hash.String())
+	if pinned {
+		err = repo.Storer.SetReference(plumbing.NewHashReference(name, commit.Hash))
+	} else {
+		err = repo.Storer.RemoveReference(name)
+	}
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPin), err)}
+	}
+
+	return models.PinnedMsg{Hash: commit.Hash.String(), Pinned: pinned}
+}
+
+// pinnedCommits returns the set of pinned commit hashes
+func pinnedCommits(repo *git.Repository) (map[plumbing.Hash]bool, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	pins := make(map[plumbing.Hash]bool)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), pinRefPrefix) {
+			pins[ref.Hash()] = true
+		}
+		return nil
+	})
+	return pins, err
+}
@@ -0,0 +1,122 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// presentRefPrefix is where the tip each branch had before the first
+// rollback of an exploration is remembered, so it can be returned to. The
+// way back belongs to the branch that was rolled back, another branch
+// started from one of its old checkpoints must not be moved there.
+const presentRefPrefix = "refs/vibegit/present/"
+
+// presentRef returns the reference holding the present of branch
+func presentRef(branch string) plumbing.ReferenceName {
+	return plumbing.ReferenceName(presentRefPrefix + branch)
+}
+
+// rememberPresent records oldHead as the present of the checked out branch
+// before a rollback. While rolling back further into its past, the first
+// one recorded is kept. A detached HEAD has no branch to return.
+func rememberPresent(repo *git.Repository, oldHead plumbing.Hash) error {
+	branch := branchName(repo)
+	if oldHead.IsZero() || branch == "" {
+		return nil
+	}
+	if present := presentHash(repo); !present.IsZero() && isAncestorOf(repo, oldHead, present) {
+		return nil
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(presentRef(branch), oldHead))
+}
+
+// presentHash returns the remembered present of the checked out branch,
+// the zero hash when there is none
+func presentHash(repo *git.Repository) plumbing.Hash {
+	branch := branchName(repo)
+	if branch == "" {
+		return plumbing.ZeroHash
+	}
+	ref, err := repo.Reference(presentRef(branch), true)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return ref.Hash()
+}
+
+// isAncestorOf reports whether commit is descendant itself or one of
+// descendant's ancestors
+func isAncestorOf(repo *git.Repository, commit, descendant plumbing.Hash) bool {
+	if commit == descendant {
+		return true
+	}
+	ancestor, err := repo.CommitObject(commit)
+	if err != nil {
+		return false
+	}
+	later, err := repo.CommitObject(descendant)
+	if err != nil {
+		return false
+	}
+	is, err := ancestor.IsAncestor(later)
+	return err == nil && is
+}
+
+// ReturnToPresent moves the checked out branch back to the tip it had
+// before its rollbacks, undoing them. Refuses when there are unsaved
+// changes to tracked files, they would be lost. New files stay where they
+// are.
+func (s *Service) ReturnToPresent() (msg tea.Msg) {
+	defer func() { s.record(models.ActivityRollback, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	head, err := repo.Head()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetHead), err)}
+	}
+
+	present := presentHash(repo)
+	if present.IsZero() || present == head.Hash() || !isAncestorOf(repo, head.Hash(), present) {
+		return models.ErrMsg{Error: errors.New(models.T(models.ErrNoPresent))}
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+	untracked, clean, err := untrackedIfClean(worktree)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+	if !clean {
+		return models.ErrMsg{Error: errors.New(models.T(models.ErrPresentUnsaved))}
+	}
+
+	// A hard reset would delete the new files, only the saved ones are moved
+	if err := moveWorktree(repo, worktree, present, untracked); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToReturn), err)}
+	}
+
+	// Back in the present there is nothing left to return to
+	_ = repo.Storer.RemoveReference(presentRef(head.Name().Short()))
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextReturnedToPresent), present.String())}
+}
@@ -0,0 +1,75 @@
+package timekeeper
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	"time-machine/internal/models"
+)
+
+func TestPresentBelongsToItsBranch(t *testing.T) {
+	isolateGitConfig(t)
+	dir := newTestRepo(t, 4, 1)
+	s := newTestService(t, dir)
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	main := branchName(repo)
+	var history []string // newest first
+	iter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for commit, err := iter.Next(); err == nil; commit, err = iter.Next() {
+		history = append(history, commit.Hash.String())
+	}
+	status := func() *models.GitStatus {
+		t.Helper()
+		status, ok := s.LoadStatus().(*models.GitStatus)
+		if !ok {
+			t.Fatal("LoadStatus failed")
+		}
+		return status
+	}
+
+	if msg, ok := s.RollbackToCheckpoint(history[1]).(models.RollbackMsg); !ok || !msg.Success {
+		t.Fatalf("rollback failed: %#v", msg)
+	}
+	if got := status().Present; got != history[0] {
+		t.Fatalf("Present after the rollback = %s, want %s", got, history[0])
+	}
+
+	// A branch off an older checkpoint has no present of its own
+	if _, ok := s.CreateBranchFromCheckpoint(history[3], "idea").(models.StatusMsg); !ok {
+		t.Fatal("creating the branch failed")
+	}
+	if got := status().Present; got != "" {
+		t.Errorf("the new branch offers a way back to %s", got)
+	}
+	if _, ok := s.ReturnToPresent().(models.ErrMsg); !ok {
+		t.Error("the new branch returned to the other branch's present")
+	}
+	if got := headHash(repo).String(); got != history[3] {
+		t.Errorf("the new branch moved to %s", got)
+	}
+
+	// Back on the rolled back branch the way back is there again
+	if _, ok := s.SwitchBranch(main).(models.StatusMsg); !ok {
+		t.Fatal("switching back failed")
+	}
+	if got := status().Present; got != history[0] {
+		t.Fatalf("Present back on %s = %s, want %s", main, got, history[0])
+	}
+	if _, ok := s.ReturnToPresent().(models.StatusMsg); !ok {
+		t.Fatal("returning to the present failed")
+	}
+	if got := headHash(repo).String(); got != history[0] {
+		t.Errorf("%s is at %s after returning, want %s", main, got, history[0])
+	}
+	if got := status().Present; got != "" {
+		t.Errorf("Present after returning = %s", got)
+	}
+}
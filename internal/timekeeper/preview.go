@@ -0,0 +1,155 @@
+package timekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"time-machine/internal/models"
+)
+
+// RollbackPreview works out what a rollback to hash would do to the working
+// tree without touching it: every tracked file that differs from the
+// checkpoint, committed or not, is listed with the way it would change
+func (s *Service) RollbackPreview(hash string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	target, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPreview), err)}
+	}
+	targetTree, err := target.Tree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPreview), err)}
+	}
+
+	paths := make(map[string]bool)
+
+	// Committed differences between HEAD and the checkpoint
+	var head plumbing.Hash
+	if ref, err := repo.Head(); err == nil {
+		head = ref.Hash()
+		headCommit, err := repo.CommitObject(head)
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPreview), err)}
+		}
+		headTree, err := headCommit.Tree()
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPreview), err)}
+		}
+		changes, err := object.DiffTree(headTree, targetTree)
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPreview), err)}
+		}
+		for _, change := range changes {
+			if change.From.Name != "" {
+				paths[change.From.Name] = true
+			}
+			if change.To.Name != "" {
+				paths[change.To.Name] = true
+			}
+		}
+	}
+
+	// Uncommitted changes are thrown away too, go-git's hard reset removes
+	// untracked files as well
+	status, err := worktree.Status()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+	unsaved := 0
+	for path, entry := range status {
+		if entry.Staging != git.Unmodified || entry.Worktree != git.Unmodified {
+			paths[path] = true
+			unsaved++
+		}
+	}
+
+	preview := models.RollbackPreview{
+		Hash:      target.Hash.String(),
+		Message:   firstLine(target.Message),
+		Unsaved:   unsaved,
+		AutoSaved: s.config.Rollback.AutoSaveBefore,
+	}
+	root := worktree.Filesystem.Root()
+	for path := range paths {
+		_, inTarget := targetTree.FindEntry(path)
+		_, onDisk := os.Lstat(filepath.Join(root, filepath.FromSlash(path)))
+
+		change := models.PreviewModified
+		switch {
+		case inTarget != nil && onDisk != nil:
+			// Gone from both, e.g. a staged file deleted from disk
+			continue
+		case inTarget != nil:
+			change = models.PreviewDeleted
+		case onDisk != nil:
+			change = models.PreviewCreated
+		}
+		preview.Files = append(preview.Files, models.PreviewFile{Path: path, Change: change})
+	}
+	sort.Slice(preview.Files, func(i, j int) bool {
+		return preview.Files[i].Path < preview.Files[j].Path
+	})
+
+	preview.Commits = summarize(repo, "", head, target.Hash).Affected
+	if preview.Commits > 0 {
+		preview.Unpushed = unpushedSkipped(repo, head, target, remoteBranchHash(repo, s.config.Sync.Remote))
+	}
+
+	return models.RollbackPreviewMsg{Preview: preview}
+}
+
+// unpushedSkipped counts the commits between target and head that the
+// remote-tracking ref doesn't contain, so a rollback would leave them
+// reachable from nowhere. Without a tracking ref every one of them counts.
+func unpushedSkipped(repo *git.Repository, head plumbing.Hash, target *object.Commit, remote plumbing.Hash) int {
+	var remoteCommit *object.Commit
+	if !remote.IsZero() {
+		remoteCommit, _ = repo.CommitObject(remote)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: head})
+	if err != nil {
+		return 0
+	}
+	defer iter.Close()
+
+	unpushed := 0
+	_ = iter.ForEach(func(commit *object.Commit) error {
+		if isAncestor, err := commit.IsAncestor(target); err != nil || isAncestor {
+			return storer.ErrStop
+		}
+		if remoteCommit != nil {
+			if pushed, err := commit.IsAncestor(remoteCommit); err == nil && pushed {
+				return nil
+			}
+		}
+		unpushed++
+		return nil
+	})
+	return unpushed
+}
@@ -0,0 +1,176 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// rebaseOnto replays the local commits made since the histories split on
+// top of remoteHash, like `git pull --rebase`. Nothing is merged line by
+// line: when both sides changed the same files the branch stays as it was
+// and those files are returned instead. Unsaved changes stop the rebase
+// before it starts.
+func (s *Service) rebaseOnto(repo *git.Repository, worktree *git.Worktree, remoteHash plumbing.Hash) (replayed int, clashes []string, err error) {
+	untracked, clean, err := untrackedIfClean(worktree)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !clean {
+		return 0, nil, errors.New(models.T(models.ErrRebaseUnsaved))
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return 0, nil, err
+	}
+	local, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return 0, nil, err
+	}
+	remote, err := repo.CommitObject(remoteHash)
+	if err != nil {
+		return 0, nil, err
+	}
+	bases, err := local.MergeBase(remote)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(bases) == 0 {
+		return 0, nil, errors.New(models.T(models.ErrRebaseUnrelated))
+	}
+
+	// The local commits since the split, newest first
+	var commits []*object.Commit
+	for commit := local; commit.Hash != bases[0].Hash; {
+		if commit.NumParents() != 1 {
+			return 0, nil, errors.New(models.T(models.ErrRebaseMerges))
+		}
+		commits = append(commits, commit)
+		if commit, err = commit.Parent(0); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if clashes, err = clashingFiles(repo, worktree, remoteHash); err != nil || len(clashes) > 0 {
+		return 0, clashes, err
+	}
+
+	// Stand on the remote commits, then put the local ones on top
+	if err := moveWorktree(repo, worktree, remoteHash, untracked); err != nil {
+		return 0, nil, err
+	}
+	for i := len(commits) - 1; i >= 0; i-- {
+		if err := s.replayCommit(repo, worktree, commits[i]); err != nil {
+			// Put the branch back the way it was
+			_ = moveWorktree(repo, worktree, local.Hash, nil)
+			return 0, nil, err
+		}
+	}
+	return len(commits), nil, nil
+}
+
+// replayCommit applies the changes commit made to its parent on top of
+// HEAD and commits them with the original author and message. A chained
+// checkpoint gets a new link, the old one named the old parent.
+func (s *Service) replayCommit(repo *git.Repository, worktree *git.Worktree, commit *object.Commit) error {
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return err
+	}
+	from, err := parent.Tree()
+	if err != nil {
+		return err
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	files, err := treeChanges(from, to)
+	if err != nil {
+		return err
+	}
+	if err := writeFiles(worktree.Filesystem.Root(), files); err != nil {
+		return err
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+	}
+	if err := stagePaths(worktree, paths); err != nil {
+		return err
+	}
+
+	message := commit.Message
+	if chainValue(message) != "" {
+		if message, err = withChainTrailer(repo, withoutChainTrailer(message)); err != nil {
+			return err
+		}
+	}
+
+	// The author stays, the committer is whoever replays it, as in git.
+	// Checkpoints keep the tool as committer, it is what marks them.
+	author := commit.Author
+	committer := s.signature(repo, models.CheckpointAuthorName, models.CheckpointAuthorEmail)
+	if isToolCheckpoint(commit) {
+		committer = &object.Signature{
+			Name:  models.CheckpointAuthorName,
+			Email: models.CheckpointAuthorEmail,
+			When:  s.now(),
+		}
+	}
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		Author:            &author,
+		Committer:         committer,
+		AllowEmptyCommits: true,
+	})
+	return err
+}
+
+// moveWorktree points the current branch at hash and brings the files and
+// the index along. Only the files that differ are written, so ignored and
+// untracked files stay; an untracked file in the way stops the move.
+func moveWorktree(repo *git.Repository, worktree *git.Worktree, hash plumbing.Hash, untracked map[string]bool) error {
+	from, err := commitTree(repo, headHash(repo))
+	if err != nil {
+		return err
+	}
+	to, err := commitTree(repo, hash)
+	if err != nil {
+		return err
+	}
+	files, err := treeChanges(from, to)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.file != nil && untracked[file.path] {
+			return fmt.Errorf(models.T(models.ErrMoveUntracked), file.path)
+		}
+	}
+	if err := writeFiles(worktree.Filesystem.Root(), files); err != nil {
+		return err
+	}
+	if err := moveHead(repo, hash); err != nil {
+		return err
+	}
+	return worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset})
+}
+
+// withoutChainTrailer drops the chain link from a commit message
+func withoutChainTrailer(message string) string {
+	lines := strings.Split(message, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(line, chainTrailer) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimRight(strings.Join(kept, "\n"), "\n")
+}
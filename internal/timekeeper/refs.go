@@ -0,0 +1,213 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// ResolveCheckpoint finds the commit that a hash prefix, branch, tag or
+// revision expression like HEAD~3 points to
+func (s *Service) ResolveCheckpoint(rev string) tea.Msg {
+	rev = strings.TrimSpace(rev)
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// go-git silently takes the first match of a short hash, git would refuse
+	if matches, err := commitsWithPrefix(repo, rev); err != nil {
+		return models.ErrMsg{Error: err}
+	} else if len(matches) > 1 {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrRefAmbiguous), rev, strings.Join(matches, ", "))}
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s %q: %w", models.T(models.ErrRefNotFound), rev, err)}
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s %q: %w", models.T(models.ErrRefNotFound), rev, err)}
+	}
+
+	tags, err := tagsByCommit(repo)
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	checkpoint := models.Checkpoint{
+		Hash:    commit.Hash.String(),
+		Message: commit.Message,
+		Author:  commit.Author.Name,
+		Date:    commit.Author.When,
+		Tags:    tags[commit.Hash],
+	}
+	if head, err := repo.Head(); err == nil {
+		checkpoint.IsCurrent = head.Hash() == commit.Hash
+	}
+
+	return models.CheckpointResolvedMsg{Checkpoint: checkpoint}
+}
+
+// CheckpointDiff builds the patch a checkpoint introduced on top of its
+// first parent
+func (s *Service) CheckpointDiff(hash string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	// The very first checkpoint is compared with nothing
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return models.ErrMsg{Error: errors.New(models.T(models.ErrParentMissing))}
+		}
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	return models.DiffMsg{
+		Title: fmt.Sprintf(models.T(models.TitleCheckpointDiff), hash, firstLine(commit.Message)),
+		Patch: patch.String(),
+	}
+}
+
+// CompareCheckpoints builds the patch between the trees of checkpoints a
+// and b. Whichever way round they are picked the patch runs from the older
+// one to the newer: from the ancestor when one descends from the other, by
+// date when they are on different lines.
+func (s *Service) CompareCheckpoints(a, b string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	from, err := repo.CommitObject(plumbing.NewHash(a))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	to, err := repo.CommitObject(plumbing.NewHash(b))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	switch {
+	case isAncestorOf(repo, from.Hash, to.Hash):
+	case isAncestorOf(repo, to.Hash, from.Hash):
+		from, to = to, from
+	case to.Committer.When.Before(from.Committer.When):
+		from, to = to, from
+	}
+
+	fromTree, err := from.Tree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToBuildDiff), err)}
+	}
+
+	return models.DiffMsg{
+		Title: fmt.Sprintf(models.T(models.TitleCompareCheckpoints), from.Hash.String(), to.Hash.String()),
+		Patch: patch.String(),
+	}
+}
+
+// commitsWithPrefix lists the short hashes of all commits starting with rev
+// when rev looks like an abbreviated hash
+func commitsWithPrefix(repo *git.Repository, rev string) ([]string, error) {
+	if rev == "" || len(rev) >= 40 || strings.Trim(strings.ToLower(rev), "0123456789abcdef") != "" {
+		return nil, nil
+	}
+	prefix := strings.ToLower(rev)
+
+	iter, err := repo.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var matches []string
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if hash := commit.Hash.String(); strings.HasPrefix(hash, prefix) {
+			matches = append(matches, hash[:7])
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// firstLine returns the subject line of a commit message
+func firstLine(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return subject
+}
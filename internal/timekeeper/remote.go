@@ -0,0 +1,130 @@
+package timekeeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"time-machine/internal/models"
+)
+
+// remoteCheckTimeout bounds a single reachability check
+const remoteCheckTimeout = 10 * time.Second
+
+// CheckRemote makes sure the remote answers and accepts our credentials
+// before sync touches anything. It lists the remote refs, the same thing
+// `git ls-remote` does, and reports network and auth failures separately.
+func (s *Service) CheckRemote(remoteName string) error {
+	pwd, err := s.workDir()
+	if err != nil {
+		return err
+	}
+
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)
+	}
+	defer s.releaseRepository(repo)
+
+	remote, err := repo.Remote(remoteName)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return errors.New(s.missingRemote(repo))
+	} else if err != nil {
+		return err
+	}
+
+	s.report(models.T("Проверяю связь с облаком..."))
+	err = s.withRetry(models.T("Проверяю связь"), func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteCheckTimeout)
+		defer cancel()
+		_, err := remote.ListContext(ctx, &git.ListOptions{})
+		return err
+	})
+
+	// A fresh remote without branches is perfectly fine to push to
+	if err == nil || errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil
+	}
+
+	var friendly *FriendlyError
+	if errors.As(Explain(err), &friendly) {
+		switch friendly.Category {
+		case models.ErrCategoryAuth:
+			return &FriendlyError{Category: friendly.Category, Text: models.T(models.ErrRemoteBadCredentials), Err: err}
+		case models.ErrCategoryNetwork:
+			return &FriendlyError{Category: friendly.Category, Text: models.T(models.ErrRemoteUnreachable), Err: err}
+		}
+	}
+	return fmt.Errorf("%s: %w", models.T(models.ErrRemoteUnreachable), err)
+}
+
+// missingRemote explains that the remote set in sync.remote doesn't exist.
+// When the repository has other remotes they are named, so the user knows
+// what to put in the setting instead.
+func (s *Service) missingRemote(repo *git.Repository) string {
+	remotes, err := repo.Remotes()
+	if err != nil || len(remotes) == 0 {
+		return models.T(models.ErrNoRemote)
+	}
+
+	names := make([]string, len(remotes))
+	for i, remote := range remotes {
+		names[i] = remote.Config().Name
+	}
+	sort.Strings(names)
+	return fmt.Sprintf(models.T(models.ErrRemoteNotFoundIn), s.config.Sync.Remote, strings.Join(names, ", "))
+}
+
+// remoteFetchInterval is how often the interface fetches the remote
+const remoteFetchInterval = time.Minute
+
+// remoteFetchTimeout keeps an unreachable remote from hanging the fetch
+const remoteFetchTimeout = 5 * time.Second
+
+// RefreshRemote fetches the remote-tracking refs the status counts ahead
+// and behind against, at most once per remoteFetchInterval. It runs apart
+// from LoadStatus so neither the status nor the CLI waits on the network.
+// Returns RemoteRefreshedMsg when the upstream of the current branch moved,
+// nil otherwise. Failures are ignored: offline, the refs from the last
+// successful fetch are still good enough to count against.
+func (s *Service) RefreshRemote() tea.Msg {
+	s.fetchMu.Lock()
+	if s.now().Sub(s.lastFetch) < remoteFetchInterval {
+		s.fetchMu.Unlock()
+		return nil
+	}
+	s.lastFetch = s.now()
+	s.fetchMu.Unlock()
+
+	pwd, err := s.workDir()
+	if err != nil {
+		return nil
+	}
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return nil
+	}
+	defer s.releaseRepository(repo)
+
+	remote, err := repo.Remote(s.config.Sync.Remote)
+	if err != nil {
+		return nil
+	}
+	before := s.upstreamHash(repo)
+	ctx, cancel := context.WithTimeout(context.Background(), remoteFetchTimeout)
+	defer cancel()
+	if err := remote.FetchContext(ctx, &git.FetchOptions{RemoteName: s.config.Sync.Remote}); err != nil {
+		return nil
+	}
+	if s.upstreamHash(repo) == before {
+		return nil
+	}
+	return models.RemoteRefreshedMsg{}
+}
@@ -0,0 +1,69 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+func TestRefreshRemoteReportsUpstreamMoves(t *testing.T) {
+	isolateGitConfig(t)
+	origin := newTestRepo(t, 1, 1)
+	dir := t.TempDir()
+	if _, err := git.PlainClone(dir, false, &git.CloneOptions{URL: origin}); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestService(t, dir)
+	clock := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+
+	if msg := s.RefreshRemote(); msg != nil {
+		t.Errorf("refresh with nothing new = %#v", msg)
+	}
+
+	// A new commit upstream is reported once the interval has passed
+	repo, err := git.PlainOpen(origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(origin, "new.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("new.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Commit("upstream", &git.CommitOptions{Author: testSignature}); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := s.RefreshRemote(); msg != nil {
+		t.Errorf("refresh within the interval = %#v", msg)
+	}
+	clock = clock.Add(remoteFetchInterval)
+	if _, ok := s.RefreshRemote().(models.RemoteRefreshedMsg); !ok {
+		t.Error("the upstream moved but the refresh reported nothing")
+	}
+
+	// Another branch moving upstream leaves the counts alone
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("other"), head.Hash())); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(remoteFetchInterval)
+	if msg := s.RefreshRemote(); msg != nil {
+		t.Errorf("refresh after another branch moved = %#v", msg)
+	}
+}
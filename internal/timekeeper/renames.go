@@ -0,0 +1,99 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// detectRenames pairs the removed files of status with the new ones that
+// have exactly the same content. go-git reports a rename as a deletion and
+// an addition, so the pairing is done here, like git's exact rename
+// detection. Staged and unstaged renames are matched separately; empty
+// files are never paired, they would all look alike.
+func detectRenames(repo *git.Repository, worktree *git.Worktree, status git.Status) []models.Rename {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil
+	}
+	indexHash := func(path string) plumbing.Hash {
+		entry, err := idx.Entry(path)
+		if err != nil || entry.Size == 0 {
+			return plumbing.ZeroHash
+		}
+		return entry.Hash
+	}
+	tree, _ := commitTree(repo, headHash(repo))
+	committedHash := func(path string) plumbing.Hash {
+		if tree == nil {
+			return plumbing.ZeroHash
+		}
+		file, err := tree.File(path)
+		if err != nil || file.Size == 0 {
+			return plumbing.ZeroHash
+		}
+		return file.Hash
+	}
+	root := worktree.Filesystem.Root()
+	worktreeHash := func(path string) plumbing.Hash {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+		if err != nil || len(content) == 0 {
+			return plumbing.ZeroHash
+		}
+		return plumbing.ComputeHash(plumbing.BlobObject, content)
+	}
+
+	// Removed files by their last known content
+	stagedFrom := map[plumbing.Hash][]string{}
+	unstagedFrom := map[plumbing.Hash][]string{}
+	for path, entry := range status {
+		switch {
+		case entry.Staging == git.Deleted:
+			if hash := committedHash(path); !hash.IsZero() {
+				stagedFrom[hash] = append(stagedFrom[hash], path)
+			}
+		case entry.Worktree == git.Deleted:
+			if hash := indexHash(path); !hash.IsZero() {
+				unstagedFrom[hash] = append(unstagedFrom[hash], path)
+			}
+		}
+	}
+	if len(stagedFrom) == 0 && len(unstagedFrom) == 0 {
+		return nil
+	}
+
+	var renames []models.Rename
+	claim := func(from map[plumbing.Hash][]string, hash plumbing.Hash, to string) {
+		paths := from[hash]
+		if hash.IsZero() || len(paths) == 0 {
+			return
+		}
+		sort.Strings(paths)
+		renames = append(renames, models.Rename{From: paths[0], To: to})
+		from[hash] = paths[1:]
+	}
+
+	// Walk the new files in order so repeated runs pair them the same way
+	added := make([]string, 0, len(status))
+	for path := range status {
+		added = append(added, path)
+	}
+	sort.Strings(added)
+	for _, path := range added {
+		entry := status[path]
+		switch {
+		case entry.Staging == git.Added:
+			claim(stagedFrom, indexHash(path), path)
+		case entry.Worktree == git.Untracked && len(unstagedFrom) > 0:
+			claim(unstagedFrom, worktreeHash(path), path)
+		}
+	}
+
+	sort.Slice(renames, func(i, j int) bool { return renames[i].To < renames[j].To })
+	return renames
+}
@@ -0,0 +1,192 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"time-machine/internal/models"
+)
+
+var (
+	// ErrLinkedWorktree is returned when a linked worktree can't be opened
+	ErrLinkedWorktree = errors.New(models.T(models.ErrLinkedWorktreeUnsupported))
+	// ErrWorkDirUnavailable is returned when the working directory was
+	// deleted or became inaccessible while the app was running
+	ErrWorkDirUnavailable = errors.New(models.T(models.ErrWorkDirUnavailable))
+)
+
+// workDir resolves the directory the service operates on: the one given
+// with SetDir, the current one otherwise
+func (s *Service) workDir() (string, error) {
+	pwd := s.dir
+	if pwd == "" {
+		var err error
+		if pwd, err = os.Getwd(); err != nil {
+			return "", ErrWorkDirUnavailable
+		}
+	}
+
+	// Getwd may still answer from $PWD for a directory that is already gone
+	if _, err := os.Stat(pwd); err != nil {
+		return "", ErrWorkDirUnavailable
+	}
+	return pwd, nil
+}
+
+// openRepository opens the repository at path. Linked worktrees created with
+// `git worktree add` keep refs and objects in the main repository, so the
+// common dir has to be enabled for go-git to see them.
+func openRepository(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil && isLinkedWorktree(path) {
+		return nil, ErrLinkedWorktree
+	}
+	return repo, err
+}
+
+// openRepository returns the repository at path, reusing the one the
+// previous operation opened. Reading the pack indexes is slow on large
+// repositories, so the cached one is replaced only when the service moved
+// to another directory or the packs changed under it, e.g. after git gc.
+// go-git fills its object storage without locking, so the cached repository
+// serves one operation at a time: a command running alongside, e.g. from a
+// tea.Batch, opens its own. Callers hand it back with releaseRepository.
+func (s *Service) openRepository(path string) (*git.Repository, error) {
+	s.repoMu.Lock()
+	defer s.repoMu.Unlock()
+
+	stale := s.repo == nil || s.repoPath != path || packsModTime(s.repo) != s.repoPacks
+	if !stale && !s.repoBusy {
+		s.repoBusy = true
+		return s.repo, nil
+	}
+
+	repo, err := openRepository(path)
+	if err != nil {
+		if stale {
+			s.repo = nil
+		}
+		return nil, err
+	}
+	if stale {
+		// Whoever still holds the old one keeps it, releasing it is a no-op
+		s.repo, s.repoPath, s.repoPacks, s.repoBusy = repo, path, packsModTime(repo), true
+	}
+	return repo, nil
+}
+
+// releaseRepository ends an operation's use of repo, making the cached
+// repository available to the next one. Repositories opened alongside it
+// are simply dropped.
+func (s *Service) releaseRepository(repo *git.Repository) {
+	s.repoMu.Lock()
+	defer s.repoMu.Unlock()
+
+	if repo != nil && repo == s.repo {
+		s.repoBusy = false
+	}
+}
+
+// packsModTime returns when the pack directory of repo last changed, the
+// zero time when it can't be read
+func packsModTime(repo *git.Repository) time.Time {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return time.Time{}
+	}
+	info, err := storage.Filesystem().Stat(path.Join("objects", "pack"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// isLinkedWorktree reports whether path is a worktree whose .git is a file
+// pointing into another repository's worktrees directory
+func isLinkedWorktree(path string) bool {
+	content, err := os.ReadFile(filepath.Join(path, git.GitDirName))
+	if err != nil {
+		return false
+	}
+
+	gitDir, found := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !found {
+		return false
+	}
+	return strings.Contains(filepath.ToSlash(gitDir), "/worktrees/")
+}
+
+// RepositoryRoot returns the top directory of the repository dir is in,
+// dir itself when it isn't in one
+func RepositoryRoot(dir string) string {
+	repo, err := openRepository(dir)
+	if err != nil {
+		return dir
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return dir
+	}
+	return worktree.Filesystem.Root()
+}
+
+// scopeOf returns dir relative to the repository root, slash separated and
+// empty when dir is the root itself or lies outside of it
+func scopeOf(root, dir string) string {
+	// Resolve symlinks like /tmp on macOS, or the paths don't line up
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// branchName returns the short name of the checked out branch, empty when
+// HEAD is detached or unborn
+func branchName(repo *git.Repository) string {
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+	return head.Name().Short()
+}
+
+// branchTip resolves the commit a local branch points to, falling back to
+// the remote-tracking branch of the sync remote. A full remote name like
+// origin/main works too.
+func (s *Service) branchTip(repo *git.Repository, branch string) (plumbing.Hash, error) {
+	local := plumbing.NewBranchReferenceName(branch)
+	if err := local.Validate(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf(models.T(models.ErrInvalidBranchName), branch)
+	}
+
+	candidates := []plumbing.ReferenceName{
+		local,
+		plumbing.NewRemoteReferenceName(s.config.Sync.Remote, branch),
+		plumbing.ReferenceName("refs/remotes/" + branch),
+	}
+	for _, name := range candidates {
+		if ref, err := repo.Reference(name, true); err == nil {
+			return ref.Hash(), nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf(models.T(models.ErrBranchNotFound), branch)
+}
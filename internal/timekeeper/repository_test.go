@@ -0,0 +1,131 @@
+package timekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/config"
+	"time-machine/internal/models"
+)
+
+// testSignature commits the fixtures, far from any identity under test
+var testSignature = &object.Signature{Name: "Fixture", Email: "fixture@example.com", When: time.Unix(1700000000, 0)}
+
+// newTestRepo creates a repository in a temporary directory with commits
+// commits, each writing files files, and returns its path
+func newTestRepo(tb testing.TB, commits, files int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for c := 0; c < commits; c++ {
+		for f := 0; f < files; f++ {
+			name := filepath.Join(dir, fmt.Sprintf("file%03d.txt", f))
+			if err := os.WriteFile(name, []byte(fmt.Sprintf("commit %d file %d\n", c, f)), 0o644); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		if _, err := worktree.Add("."); err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := worktree.Commit(fmt.Sprintf("commit %d", c), &git.CommitOptions{Author: testSignature}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// newTestService returns a service working in dir with the default config
+func newTestService(tb testing.TB, dir string) *Service {
+	tb.Helper()
+	s := NewService(config.Default())
+	if err := s.SetDir(dir); err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+func TestOpenRepositoryLendsCacheToOneOperation(t *testing.T) {
+	dir := newTestRepo(t, 1, 1)
+	s := newTestService(t, dir)
+
+	first, err := s.openRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.openRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("two operations running at once share one repository")
+	}
+
+	s.releaseRepository(second)
+	s.releaseRepository(first)
+	third, err := s.openRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.releaseRepository(third)
+	if third != first {
+		t.Fatal("the cached repository isn't reused once released")
+	}
+}
+
+func TestLoadStatusConcurrently(t *testing.T) {
+	s := newTestService(t, newTestRepo(t, 3, 5))
+
+	results := make(chan interface{}, 8)
+	for i := 0; i < cap(results); i++ {
+		go func() { results <- s.LoadStatus() }()
+	}
+	for i := 0; i < cap(results); i++ {
+		if msg := <-results; msg == nil {
+			t.Fatal("LoadStatus returned nothing")
+		} else if _, ok := msg.(*models.GitStatus); !ok {
+			t.Fatalf("LoadStatus returned %#v", msg)
+		}
+	}
+}
+
+// benchmarkLoadStatus repeats LoadStatus on a packed repository. fresh
+// drops the cached repository before every call, as when each operation
+// opened its own.
+func benchmarkLoadStatus(b *testing.B, fresh bool) {
+	dir := newTestRepo(b, 20, 200)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := repo.RepackObjects(&git.RepackConfig{}); err != nil {
+		b.Fatal(err)
+	}
+	s := newTestService(b, dir)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if fresh {
+			s.repo = nil
+		}
+		if _, ok := s.LoadStatus().(*models.GitStatus); !ok {
+			b.Fatal("LoadStatus failed")
+		}
+	}
+}
+
+func BenchmarkLoadStatusCached(b *testing.B) { benchmarkLoadStatus(b, false) }
+
+func BenchmarkLoadStatusFresh(b *testing.B) { benchmarkLoadStatus(b, true) }
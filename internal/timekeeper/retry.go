@@ -0,0 +1,62 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+
+	"time-machine/internal/models"
+)
+
+// withRetry runs op and retries transient network failures with exponential
+// backoff. Successful results and sentinel "errors" like NoErrAlreadyUpToDate
+// are returned right away, as are failures that a retry can't fix.
+func (s *Service) withRetry(action string, op func() error) error {
+	delay := time.Duration(s.config.Sync.RetryDelayMs) * time.Millisecond
+
+	err := op()
+	for attempt := 1; attempt <= s.config.Sync.Retries && isTransient(err); attempt++ {
+		s.report(fmt.Sprintf(models.T("%s: сеть моргнула, попытка %d из %d..."), action, attempt+1, s.config.Sync.Retries+1))
+		time.Sleep(delay)
+		delay *= 2
+		err = op()
+	}
+	return err
+}
+
+// isTransient reports whether err looks like a network hiccup worth retrying
+func isTransient(err error) bool {
+	if err == nil || err == git.NoErrAlreadyUpToDate {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ETIMEDOUT) ||
+		errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH) {
+		return true
+	}
+
+	// go-git flattens some transport errors into plain strings
+	message := strings.ToLower(err.Error())
+	for _, hint := range []string{"timeout", "timed out", "connection reset", "temporary failure", "unexpected eof"} {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
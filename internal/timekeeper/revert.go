@@ -0,0 +1,151 @@
+package timekeeper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// RevertToCheckpoint brings the files back to the checkpoint hash with a new
+// checkpoint on top, the way `git revert` undoes a range. Unlike a rollback
+// nothing leaves the history: the later checkpoints stay on the branch and
+// unsaved changes in the files the revert doesn't touch stay unsaved.
+func (s *Service) RevertToCheckpoint(hash string) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityRevert, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	target, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+
+	head, files, touched, err := revertPlan(repo, worktree, target)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+
+	// Unsaved edits in the files being brought back would be lost, save
+	// just those files first or leave it to the user
+	if len(touched) > 0 {
+		if !s.config.Rollback.AutoSaveBefore {
+			return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrRevertUnsaved), strings.Join(touched, ", "))}
+		}
+		saved := s.CreateCheckpoint(fmt.Sprintf(models.T(models.PreRollbackMessage), hash), CheckpointOptions{
+			Paths:     touched,
+			Confirmed: true,
+		})
+		if failed, ok := saved.(models.ErrMsg); ok {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSaveBeforeRollback), failed.Error)}
+		}
+		if head, files, _, err = revertPlan(repo, worktree, target); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+		}
+	}
+	if len(files) == 0 {
+		return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextNothingToRevert), hash)}
+	}
+
+	message := fmt.Sprintf(models.T(models.RevertCommitMessage), hash, firstLine(target.Message))
+	if s.config.Checkpoint.Chain {
+		if message, err = withChainTrailer(repo, message); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+		}
+	}
+
+	// Authored by the user and committed by the tool, like any checkpoint
+	author := s.signature(repo, models.CheckpointAuthorName, models.CheckpointAuthorEmail)
+	commit := &object.Commit{
+		Author: *author,
+		Committer: object.Signature{
+			Name:  models.CheckpointAuthorName,
+			Email: models.CheckpointAuthorEmail,
+			When:  author.When,
+		},
+		Message:      message,
+		TreeHash:     target.TreeHash,
+		ParentHashes: []plumbing.Hash{head},
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+	revert, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+
+	// Only the reverted files change, in the worktree and in the index
+	if err := moveHead(repo, revert); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+	if err := writeFiles(worktree.Filesystem.Root(), files); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+	}
+	if err := stagePaths(worktree, paths); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRevert), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextReverted), hash, revert.String())}
+}
+
+// revertPlan lists the files that differ between HEAD and target, and the
+// ones among them with unsaved changes
+func revertPlan(repo *git.Repository, worktree *git.Worktree, target *object.Commit) (plumbing.Hash, []stashFile, []string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, nil, nil, err
+	}
+	from, err := commitTree(repo, head.Hash())
+	if err != nil {
+		return plumbing.ZeroHash, nil, nil, err
+	}
+	to, err := target.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, nil, nil, err
+	}
+	files, err := treeChanges(from, to)
+	if err != nil {
+		return plumbing.ZeroHash, nil, nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return plumbing.ZeroHash, nil, nil, err
+	}
+	var touched []string
+	for _, file := range files {
+		if entry, ok := status[file.path]; ok && (entry.Staging != git.Unmodified || entry.Worktree != git.Unmodified) {
+			touched = append(touched, file.path)
+		}
+	}
+	sort.Strings(touched)
+	return head.Hash(), files, touched, nil
+}
@@ -1,44 +1,168 @@
 package timekeeper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbletea"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 
+	"time-machine/internal/config"
 	"time-machine/internal/models"
 )
 
 // Service provides git operations
-type Service struct{}
+type Service struct {
+	// base is the config as loaded, config has the active profile applied
+	base     config.Config
+	config   config.Config
+	progress func(text string)
+	// now stamps the commits the service makes, tests swap it for a fixed clock
+	now func() time.Time
+	// activityMu serializes writes to the activity log
+	activityMu sync.Mutex
+	// activityFeed receives every entry as it is recorded
+	activityFeed func(entry models.ActivityEntry)
+	// cancel interrupts the running cancelable operation
+	cancelMu sync.Mutex
+	cancel   func()
+	// lastFetch is when the status last refreshed the remote-tracking refs
+	fetchMu   sync.Mutex
+	lastFetch time.Time
+	// repo is the repository opened at repoPath, kept between operations
+	// while the packs it indexed are unchanged. repoBusy is set while an
+	// operation uses it.
+	repoMu    sync.Mutex
+	repo      *git.Repository
+	repoPath  string
+	repoPacks time.Time
+	repoBusy  bool
+	// dir is the directory the service works in, empty for the current one
+	dir string
+	// history is the walk behind the history loaded last, the pages
+	// LoadMoreCheckpoints adds continue it
+	historyMu sync.Mutex
+	history   *historySession
+}
+
+// CheckpointOptions tunes how a checkpoint is created
+type CheckpointOptions struct {
+	// Paths limits the checkpoint to the listed files, nil includes every change
+	Paths []string
+	// AllowEmpty creates a marker checkpoint even when nothing changed
+	AllowEmpty bool
+	// AuthorName and AuthorEmail replace the author for this checkpoint
+	// only, e.g. when pairing or in CI. A checkpoint with another email
+	// no longer counts as a tool checkpoint when squashing.
+	AuthorName  string
+	AuthorEmail string
+	// StagedOnly commits the index exactly as it was staged, leaving
+	// unstaged changes out. Paths is ignored.
+	StagedOnly bool
+	// SkipUntracked leaves new files out when Paths is nil
+	SkipUntracked bool
+	// Date backdates the checkpoint, e.g. when reconstructing a timeline.
+	// The zero value stamps it with the current time.
+	Date time.Time
+	// Confirmed skips asking about changesets larger than
+	// checkpoint.confirmFiles
+	Confirmed bool
+}
 
 // NewService creates a new git service
-func NewService() *Service {
-	return &Service{}
+func NewService(cfg config.Config) *Service {
+	s := &Service{base: cfg, config: cfg, now: time.Now}
+	if profiled, err := cfg.WithProfile(cfg.Profile); err == nil {
+		s.config = profiled
+	}
+	return s
+}
+
+// Profiles returns the names of the configured profiles
+func (s *Service) Profiles() []string {
+	return s.base.ProfileNames()
+}
+
+// IncludeUntracked reports whether checkpoints take new files by default
+func (s *Service) IncludeUntracked() bool {
+	return s.config.Checkpoint.IncludeUntracked
+}
+
+// ActiveProfile returns the name of the profile in use, empty for none
+func (s *Service) ActiveProfile() string {
+	return s.config.Profile
+}
+
+// UseProfile switches to the named profile, an empty name drops back to
+// the plain config
+func (s *Service) UseProfile(name string) error {
+	profiled, err := s.base.WithProfile(name)
+	if err != nil {
+		return err
+	}
+	s.config = profiled
+	return nil
+}
+
+// SetProgress registers a callback receiving progress updates of long operations
+func (s *Service) SetProgress(fn func(text string)) {
+	s.progress = fn
+}
+
+// SetDir makes the service work in dir instead of the current directory,
+// like git -C. dir must be an existing directory.
+func (s *Service) SetDir(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf(models.T(models.ErrDirNotFound), dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf(models.T(models.ErrNotADirectory), dir)
+	}
+	s.dir = abs
+	return nil
+}
+
+// report sends a progress update if anyone is listening
+func (s *Service) report(text string) {
+	if s.progress != nil {
+		s.progress(text)
+	}
 }
 
 // LoadStatus loads the current git repository status
 func (s *Service) LoadStatus() tea.Msg {
 	// Get current directory
-	pwd, err := os.Getwd()
+	pwd, err := s.workDir()
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
 	// Open git repository
-	repo, err := git.PlainOpen(pwd)
+	repo, err := s.openRepository(pwd)
 	if err != nil {
 		if err == git.ErrRepositoryNotExists {
 			return models.GitNotInitializedMsg{
-				Message: "Машина времени не запущена в этой папке",
+				Message: models.T("Машина времени не запущена в этой папке"),
 			}
 		}
 		return models.ErrMsg{Error: err}
 	}
+	defer s.releaseRepository(repo)
 
 	// Get worktree status
 	worktree, err := repo.Worktree()
@@ -46,73 +170,132 @@ func (s *Service) LoadStatus() tea.Msg {
 		return models.ErrMsg{Error: err}
 	}
 
-	status, err := worktree.Status()
+	status, large, err := statusSkippingLarge(repo, worktree, s.config.Status.LargeFileMB<<20)
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
+	gitStatus := &models.GitStatus{
+		Root:    worktree.Filesystem.Root(),
+		Scope:   scopeOf(worktree.Filesystem.Root(), pwd),
+		Branch:  "master", // Default branch name
+		IsClean: true,
+		Large:   large,
+	}
+
 	// Get current branch
 	ref, err := repo.Head()
-	if err != nil {
-		// Handle case where there are no commits yet
-		if err == plumbing.ErrReferenceNotFound {
-			// Repository is initialized but has no commits
-			gitStatus := &models.GitStatus{
-				Branch:     "master", // Default branch name
-				IsClean:    status.IsClean(),
-				LastCommit: "Нет моментов",
-			}
-			return gitStatus
+	switch err {
+	case nil:
+		gitStatus.Branch = ref.Name().Short()
+
+		// Get last commit info
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return models.ErrMsg{Error: err}
 		}
+		gitStatus.LastCommitMessage = strings.TrimSpace(commit.Message)
+		gitStatus.LastCommitHash = commit.Hash.String()
+		gitStatus.LastCommitDate = commit.Author.When
+		gitStatus.LastCommitAuthor = commit.Author.Name
+	case plumbing.ErrReferenceNotFound:
+		// Repository is initialized but has no commits
+	default:
 		return models.ErrMsg{Error: err}
 	}
 
-	branchName := ref.Name().Short()
-	if ref.Name().IsBranch() {
-		branchName = string(ref.Name().Short())
-	}
-
-	// Get last commit info
-	commit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return models.ErrMsg{Error: err}
-	}
-
-	// Build status object
-	gitStatus := &models.GitStatus{
-		Branch:     branchName,
-		IsClean:    status.IsClean(),
-		LastCommit: fmt.Sprintf("%s %.7s", commit.Message, commit.Hash.String()[:7]),
-	}
-
 	// Categorize files
 	for file, entry := range status {
+		if entry.Staging == git.Unmodified && entry.Worktree == git.Unmodified {
+			continue
+		}
+
+		// Noise files are listed but don't make the tree dirty
+		if s.config.Status.IsNoise(file) {
+			gitStatus.Noise = append(gitStatus.Noise, file)
+		} else {
+			gitStatus.IsClean = false
+		}
+
+		if isStaged(entry) {
+			gitStatus.Staged = append(gitStatus.Staged, file)
+		}
 		switch entry.Worktree {
 		case git.Modified:
 			gitStatus.Modified = append(gitStatus.Modified, file)
-		case git.Added:
-			gitStatus.Staged = append(gitStatus.Staged, file)
 		case git.Untracked:
 			gitStatus.Untracked = append(gitStatus.Untracked, file)
+		case git.Deleted:
+			gitStatus.Deleted = append(gitStatus.Deleted, file)
 		}
 	}
 
+	// Keep a stable order for the navigable file list
+	sort.Strings(gitStatus.Staged)
+	sort.Strings(gitStatus.Modified)
+	sort.Strings(gitStatus.Untracked)
+	sort.Strings(gitStatus.Deleted)
+	sort.Strings(gitStatus.Noise)
+	gitStatus.Renamed = detectRenames(repo, worktree, status)
+	sort.Strings(gitStatus.Large)
+
+	// Submodule contents are never checkpointed, surface them instead
+	gitStatus.Submodules = loadSubmodules(worktree)
+
+	gitStatus.MissingIdentity = s.identityMissing(repo)
+
+	if ref != nil {
+		gitStatus.Unpushed = unpushedCheckpoints(repo, ref.Hash(), remoteBranchHash(repo, s.config.Sync.Remote))
+
+		// Compare with the upstream as of the last fetch, the status itself
+		// never goes online
+		if upstream := s.upstreamHash(repo); !upstream.IsZero() {
+			gitStatus.Ahead = countNew(repo, ref.Hash(), upstream)
+			gitStatus.Behind = countNew(repo, upstream, ref.Hash())
+		}
+	}
+
+	// After a rollback the tip it left behind can be returned to
+	if present := presentHash(repo); !present.IsZero() && ref != nil &&
+		present != ref.Hash() && isAncestorOf(repo, ref.Hash(), present) {
+		gitStatus.Present = present.String()
+	}
+
+	// A half-done merge needs finishing, not just another checkpoint
+	if heads, err := mergeHeads(repo); err == nil {
+		gitStatus.MergeInProgress = len(heads) > 0
+	}
+
 	return gitStatus
 }
 
 // CreateCheckpoint creates a new checkpoint with the given description
-func (s *Service) CreateCheckpoint(description string) tea.Msg {
+func (s *Service) CreateCheckpoint(description string, opts CheckpointOptions) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityCheckpoint, msg) }()
+
+	if opts.AuthorEmail != "" {
+		if err := validateEmail(opts.AuthorEmail); err != nil {
+			return models.ErrMsg{Error: err}
+		}
+	}
+
+	// A backdated checkpoint can't come from the future
+	if opts.Date.After(s.now()) {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrDateInFuture), opts.Date.Format(time.RFC3339))}
+	}
+
 	// Get current directory
-	pwd, err := os.Getwd()
+	pwd, err := s.workDir()
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
 	// Open git repository
-	repo, err := git.PlainOpen(pwd)
+	repo, err := s.openRepository(pwd)
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
+	defer s.releaseRepository(repo)
 
 	// Get worktree
 	worktree, err := repo.Worktree()
@@ -120,98 +303,363 @@ func (s *Service) CreateCheckpoint(description string) tea.Msg {
 		return models.ErrMsg{Error: err}
 	}
 
-	// Add all changes
-	_, err = worktree.Add(".")
+	// Checkpoints are authored by the user and committed by the tool, which
+	// is how tool checkpoints are told apart from hand-made commits
+	author := s.signature(repo, models.CheckpointAuthorName, models.CheckpointAuthorEmail)
+	committer := &object.Signature{
+		Name:  models.CheckpointAuthorName,
+		Email: models.CheckpointAuthorEmail,
+	}
+	if opts.AuthorName != "" {
+		author.Name = opts.AuthorName
+	}
+	if opts.AuthorEmail != "" {
+		author.Email = opts.AuthorEmail
+		own := *author
+		committer = &own
+	}
+
+	// A find-and-replace gone wrong touches everything, make sure it's meant
+	if limit := s.config.Checkpoint.ConfirmFiles; limit > 0 && !opts.Confirmed {
+		count, err := pendingFileCount(worktree, opts)
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+		}
+		if count > limit {
+			return models.ConfirmCheckpointMsg{Files: count, Limit: limit}
+		}
+	}
+
+	switch {
+	case opts.StagedOnly:
+		// Respect what was deliberately staged
+	case opts.Paths == nil && opts.SkipUntracked:
+		// Only changes to files git already knows
+		err = addTracked(worktree)
+	case opts.Paths == nil:
+		// Add all changes
+		_, err = worktree.Add(".")
+	default:
+		// Commit exactly the selected set
+		err = scopeIndex(repo, worktree, opts.Paths)
+	}
 	if err != nil {
-		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToAddFiles, err)}
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToAddFiles), err)}
+	}
+
+	// go-git happily commits an unchanged index, so refuse unless a marker was asked for
+	if !opts.AllowEmpty {
+		status, err := worktree.Status()
+		if err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+		}
+		if !hasStagedChanges(status) {
+			return models.CheckpointCreatedMsg{
+				Success: false,
+				Message: models.T(models.TextNothingToSave),
+			}
+		}
+	}
+
+	if s.config.Checkpoint.Chain {
+		if description, err = withChainTrailer(repo, description); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToCreateCheckpoint), err)}
+		}
 	}
 
 	// Create commit with custom message
+	author.When = s.now()
+	if !opts.Date.IsZero() {
+		author.When = opts.Date
+	}
+	committer.When = author.When
 	commit, err := worktree.Commit(description, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  models.CheckpointAuthorName,
-			Email: models.CheckpointAuthorEmail,
-			When:  time.Now(),
-		},
+		Author:            author,
+		Committer:         committer,
+		AllowEmptyCommits: opts.AllowEmpty,
 	})
 	if err != nil {
-		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToCreateCheckpoint, err)}
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToCreateCheckpoint), err)}
 	}
 
 	return models.CheckpointCreatedMsg{
 		Success: true,
-		Message: fmt.Sprintf("Момент зафиксирован: %.7s", commit.String()),
+		Message: fmt.Sprintf(models.T("Момент зафиксирован: %.7s"), commit.String()),
 	}
 }
 
-// LoadCheckpoints loads the commit history
-func (s *Service) LoadCheckpoints() tea.Msg {
+// historyProgressStep is how many loaded checkpoints go between progress reports
+const historyProgressStep = 500
+
+// HistoryPageLimit is how many checkpoints the history loads at a time
+const HistoryPageLimit = 50
+
+// LoadCheckpoints loads the newest limit checkpoints of the history, the
+// whole of it when limit isn't positive
+func (s *Service) LoadCheckpoints(limit int) tea.Msg {
+	return s.LoadBranchCheckpoints("", limit)
+}
+
+// LoadBranchCheckpoints loads the history of branch without checking it
+// out, an empty branch loads the history of HEAD
+func (s *Service) LoadBranchCheckpoints(branch string, limit int) tea.Msg {
 	// Get current directory
-	pwd, err := os.Getwd()
+	pwd, err := s.workDir()
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
 	// Open git repository
-	repo, err := git.PlainOpen(pwd)
+	repo, err := s.openRepository(pwd)
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
+	defer s.releaseRepository(repo)
 
-	// Get current HEAD
-	head, err := repo.Head()
+	// A repository without commits has no history yet
+	if headHash(repo).IsZero() && branch == "" {
+		return models.CheckpointsLoadedMsg{Checkpoints: []models.Checkpoint{}, Empty: true}
+	}
+
+	// The checked out branch is just the usual history
+	if branch == branchName(repo) {
+		branch = ""
+	}
+	start, err := s.historyStart(repo, branch)
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
-	// Get commit iterator
-	commitIter, err := repo.Log(&git.LogOptions{
-		From:  head.Hash(),
-		Order: git.LogOrderCommitterTime,
-	})
+	session, err := newHistorySession(repo, start, branch)
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Huge histories take a while, the user can give up on them
+	ctx, done := s.cancelable()
+	defer done()
+
+	page, err := s.loadHistory(ctx, repo, session, limit)
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Later pages continue this history
+	s.historyMu.Lock()
+	s.history = session
+	s.historyMu.Unlock()
+
+	return models.CheckpointsLoadedMsg{
+		Checkpoints: page.checkpoints,
+		HasMore:     page.more,
+		Shallow:     page.truncated || isShallow(repo),
+		Canceled:    ctx.Err() != nil,
+		Branch:      branch,
+	}
+}
+
+// LoadMoreCheckpoints loads the next n checkpoints of the history loaded
+// last, the ones after afterHash
+func (s *Service) LoadMoreCheckpoints(afterHash string, n int) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+	defer s.releaseRepository(repo)
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	// Pages load in the background while the history is browsed, there is
+	// no loading screen to give up on them from
+	ctx := context.Background()
+	after := plumbing.NewHash(afterHash)
+	session := s.history
+	if session == nil || session.last != after {
+		// The history was reloaded meanwhile, a page after afterHash takes
+		// a walk of its own that skips to it
+		branch := ""
+		if session != nil {
+			branch = session.branch
+		}
+		start, err := s.historyStart(repo, branch)
+		if err != nil {
+			return models.ErrMsg{Error: err}
+		}
+		if session, err = newHistorySession(repo, start, branch); err != nil {
+			return models.ErrMsg{Error: err}
+		}
+		if err := session.skipTo(ctx, repo, after); err != nil {
+			return models.ErrMsg{Error: err}
+		}
+	}
+
+	page, err := s.loadHistory(ctx, repo, session, n)
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
-	defer commitIter.Close()
 
-	var checkpoints []models.Checkpoint
-	currentHash := head.Hash().String()
+	return models.MoreCheckpointsLoadedMsg{
+		After:       afterHash,
+		Checkpoints: page.checkpoints,
+		HasMore:     page.more,
+		Shallow:     page.truncated || isShallow(repo),
+	}
+}
+
+// historyStart returns the commit the history of branch starts from, HEAD
+// for an empty branch
+func (s *Service) historyStart(repo *git.Repository, branch string) (plumbing.Hash, error) {
+	if branch != "" {
+		return s.branchTip(repo, branch)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return head.Hash(), nil
+}
+
+// historySession is a history being loaded a page at a time: the walk
+// stops after each page and the next one picks it up, and the tags, pins
+// and notes shown along are read once for all pages
+type historySession struct {
+	branch string
+	walker *historyWalker
+	// last is the newest commit handed out, the next page follows it
+	last    plumbing.Hash
+	current plumbing.Hash
+	tags    map[plumbing.Hash][]string
+	pins    map[plumbing.Hash]bool
+	notes   map[plumbing.Hash]string
+}
+
+// newHistorySession starts loading the history of branch from start
+func newHistorySession(repo *git.Repository, start plumbing.Hash, branch string) (*historySession, error) {
+	walker, err := newHistoryWalker(repo, start)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := tagsByCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := pinnedCommits(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := readNotes(repo)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", models.T(models.ErrFailedToReadNotes), err)
+	}
+
+	return &historySession{
+		branch:  branch,
+		walker:  walker,
+		current: headHash(repo),
+		tags:    tags,
+		pins:    pins,
+		notes:   notes,
+	}, nil
+}
+
+// skipTo walks past the commits up to and including after, as if the
+// pages up to it had been loaded. A walk that never meets after ends.
+func (h *historySession) skipTo(ctx context.Context, repo *git.Repository, after plumbing.Hash) error {
+	for ctx.Err() == nil {
+		commit, err := h.walker.next(repo)
+		if err != nil || commit == nil {
+			return err
+		}
+		if commit.Hash == after {
+			h.last = after
+			return nil
+		}
+	}
+	return nil
+}
+
+// historyPage is a stretch of the history as loadHistory found it
+type historyPage struct {
+	checkpoints []models.Checkpoint
+	// more is set when older checkpoints are left
+	more      bool
+	truncated bool
+}
+
+// loadHistory continues the walk of session by limit checkpoints, to the
+// end of the history when limit isn't positive. The walk stops early when
+// ctx is done.
+func (s *Service) loadHistory(ctx context.Context, repo *git.Repository, session *historySession, limit int) (historyPage, error) {
+	var page historyPage
+
+	// Report how far along a long first load is
+	first := session.last.IsZero()
+	checkpoints := []models.Checkpoint{}
+	for (limit <= 0 || len(checkpoints) < limit) && ctx.Err() == nil {
+		commit, err := session.walker.next(repo)
+		if err != nil {
+			return page, err
+		}
+		if commit == nil {
+			break
+		}
+		if first && len(checkpoints) > 0 && len(checkpoints)%historyProgressStep == 0 {
+			s.report(fmt.Sprintf(models.T(models.TextHistoryProgress), len(checkpoints)))
+		}
 
-	err = commitIter.ForEach(func(commit *object.Commit) error {
 		// Show all commits without filtering
 		checkpoint := models.Checkpoint{
 			Hash:      commit.Hash.String(),
 			Message:   commit.Message,
 			Author:    commit.Author.Name,
+			Tool:      isToolCheckpoint(commit),
 			Date:      commit.Author.When,
-			IsCurrent: commit.Hash.String() == currentHash,
+			IsCurrent: commit.Hash == session.current,
+			Tags:      session.tags[commit.Hash],
+			Pinned:    session.pins[commit.Hash],
+			Note:      session.notes[commit.Hash],
 		}
 		checkpoints = append(checkpoints, checkpoint)
-		return nil
-	})
-
-	if err != nil {
-		return models.ErrMsg{Error: err}
+		session.last = commit.Hash
 	}
 
-	return models.CheckpointsLoadedMsg{
-		Checkpoints: checkpoints,
+	more, err := session.walker.more(repo)
+	if err != nil {
+		return page, err
 	}
+	page.checkpoints = checkpoints
+	page.more = more && limit > 0 && len(checkpoints) == limit
+	page.truncated = session.walker.truncated
+	return page, nil
 }
 
 // RollbackToCheckpoint rolls back to a specific checkpoint
-func (s *Service) RollbackToCheckpoint(hash string) tea.Msg {
+func (s *Service) RollbackToCheckpoint(hash string) (msg tea.Msg) {
+	defer func() { s.record(models.ActivityRollback, msg) }()
+
 	// Get current directory
-	pwd, err := os.Getwd()
+	pwd, err := s.workDir()
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
 	// Open git repository
-	repo, err := git.PlainOpen(pwd)
+	repo, err := s.openRepository(pwd)
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
+	defer s.releaseRepository(repo)
 
 	// Get worktree
 	worktree, err := repo.Worktree()
@@ -222,6 +670,20 @@ func (s *Service) RollbackToCheckpoint(hash string) tea.Msg {
 	// Parse hash
 	commitHash := plumbing.NewHash(hash)
 
+	// Remember where we were for the summary, the backup saved next isn't
+	// something the rollback drops
+	oldHead := headHash(repo)
+
+	// Keep the changes the reset would throw away
+	var backup string
+	if s.config.Rollback.AutoSaveBefore {
+		if backup, err = s.saveBeforeRollback(repo, worktree, hash); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSaveBeforeRollback), err)}
+		}
+	}
+	// The way back leads to the backup, it is the newest state
+	present := headHash(repo)
+
 	// Reset to the checkpoint
 	err = worktree.Reset(&git.ResetOptions{
 		Commit: commitHash,
@@ -230,150 +692,281 @@ func (s *Service) RollbackToCheckpoint(hash string) tea.Msg {
 	if err != nil {
 		return models.RollbackMsg{
 			Success: false,
-			Message: fmt.Sprintf("Не удалось перемотать: %v", err),
+			Message: fmt.Sprintf(models.T("Не удалось перемотать: %v"), err),
 		}
 	}
 
+	// Keep the way back, losing it only costs the shortcut
+	_ = rememberPresent(repo, present)
+
 	return models.RollbackMsg{
 		Success: true,
-		Message: fmt.Sprintf("Успешно перемотали к моменту: %.7s", hash),
+		Message: fmt.Sprintf(models.T("Успешно перемотали к моменту: %.7s"), hash),
+		Summary: summarize(repo, models.T(models.TitleSummaryRollback), oldHead, commitHash),
+		Backup:  backup,
 	}
 }
 
-// SyncWithRemote performs pull and push operations with simple conflict handling
-func (s *Service) SyncWithRemote() tea.Msg {
+// backupBranchPrefix names the branches keeping the changes saved before a
+// rollback
+const backupBranchPrefix = "vibegit-backup/"
+
+// saveBeforeRollback checkpoints the unsaved changes a hard reset loses.
+// go-git's reset removes untracked files as well, so they are saved too
+// unless checkpoints leave them out. The reset drops the checkpoint from
+// the branch, so a vibegit-backup/<time> branch keeps it from being lost
+// once the way back to the present is gone. It returns the branch name,
+// empty when there was nothing to save.
+func (s *Service) saveBeforeRollback(repo *git.Repository, worktree *git.Worktree, hash string) (string, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return "", err
+	}
+	if status.IsClean() {
+		return "", nil
+	}
+
+	msg := s.CreateCheckpoint(fmt.Sprintf(models.T(models.PreRollbackMessage), hash), CheckpointOptions{
+		SkipUntracked: !s.config.Checkpoint.IncludeUntracked,
+		Confirmed:     true,
+	})
+	if failed, ok := msg.(models.ErrMsg); ok {
+		return "", failed.Error
+	}
+	if created, ok := msg.(models.CheckpointCreatedMsg); ok && !created.Success {
+		// Only ignored or left out files changed, nothing to keep
+		return "", nil
+	}
+
+	// Two rollbacks within a second mustn't share a branch, the second
+	// would take the first one's backup away
+	stamp := backupBranchPrefix + s.now().Format("20060102-150405")
+	backup := stamp
+	for i := 2; ; i++ {
+		if _, err := repo.Reference(plumbing.NewBranchReferenceName(backup), false); err != nil {
+			break
+		}
+		backup = fmt.Sprintf("%s-%d", stamp, i)
+	}
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(backup), headHash(repo))
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// SyncWithRemote performs pull and push operations with simple conflict
+// handling. When both sides have new commits sync.strategy decides: merge
+// resolves it as a conflict, rebase replays the local commits on top of
+// the remote ones and ff-only stops. Only merge ever force pushes.
+func (s *Service) SyncWithRemote() (msg tea.Msg) {
+	defer func() { s.record(models.ActivitySync, msg) }()
+
+	strategy := s.config.Sync.Strategy
+	switch strategy {
+	case "":
+		strategy = config.StrategyMerge
+	case config.StrategyMerge, config.StrategyRebase, config.StrategyFFOnly:
+	default:
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrUnknownStrategy), strategy)}
+	}
+
 	// Get current directory
-	pwd, err := os.Getwd()
+	pwd, err := s.workDir()
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
 
 	// Open git repository
-	repo, err := git.PlainOpen(pwd)
+	repo, err := s.openRepository(pwd)
 	if err != nil {
-		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToOpenRepo, err)}
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
 	}
+	defer s.releaseRepository(repo)
 
 	// Get worktree
 	worktree, err := repo.Worktree()
 	if err != nil {
-		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToGetWorktree, err)}
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
 	}
 
 	// Get remote
-	remote, err := repo.Remote("origin")
+	remote, err := repo.Remote(s.config.Sync.Remote)
 	if err != nil {
 		// Return a user-friendly message instead of an error
 		return models.SyncMsg{
 			Success: false,
-			Message: models.ErrNoRemote,
+			Message: s.missingRemote(repo),
 			Pulled:  false,
 			Pushed:  false,
 		}
 	}
 
+	// Fail early and precisely instead of halfway through the sync
+	if err := s.CheckRemote(remote.Config().Name); err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Whichever way it went, say how the sides were brought together
+	defer func() {
+		if sync, ok := msg.(models.SyncMsg); ok {
+			sync.Message += fmt.Sprintf(models.T(models.TextSyncStrategy), strategy)
+			msg = sync
+		}
+	}()
+
 	syncMsg := models.SyncMsg{Success: true}
+	before := headHash(repo)
 
 	// First, try to pull from remote
-	fmt.Println("Pulling from remote...")
-	pullErr := worktree.Pull(&git.PullOptions{
-		RemoteName: "origin",
+	s.report(models.T("Забираю изменения из облака..."))
+	pullErr := s.withRetry(models.T("Забираю изменения"), func() error {
+		return worktree.Pull(&git.PullOptions{
+			RemoteName: s.config.Sync.Remote,
+		})
 	})
 
 	if pullErr != nil {
-		if pullErr == git.NoErrAlreadyUpToDate {
-			syncMsg.Message = models.ErrAlreadyUpToDate
+		if pullErr == git.NoErrAlreadyUpToDate || errors.Is(pullErr, transport.ErrEmptyRemoteRepository) {
+			// A fresh remote has nothing to pull yet, the push fills it
+			syncMsg.Message = models.T(models.ErrAlreadyUpToDate)
 			syncMsg.Pulled = false
-		} else {
-			// Handle conflicts by forcing our changes (simple approach for vibecoders)
-			fmt.Println("Conflicts detected, forcing local changes...")
-
-			// Add all changes and commit if there are any
-			status, err := worktree.Status()
+		} else if isConnectionError(pullErr) {
+			// There is nothing to resolve when the remote can't be reached
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPull), pullErr)}
+		} else if errors.Is(pullErr, git.ErrNonFastForwardUpdate) && strategy == config.StrategyFFOnly {
+			// Diverged branches are left for the user to bring together
+			return models.SyncMsg{Success: false, Message: models.T(models.ErrSyncDiverged)}
+		} else if errors.Is(pullErr, git.ErrNonFastForwardUpdate) && strategy == config.StrategyRebase {
+			remoteHash, err := s.fetchRemoteBranch(repo, remote)
 			if err != nil {
-				return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToGetStatus, err)}
+				return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToFetch), err)}
 			}
-
-			if !status.IsClean() {
-				// Add all changes
-				_, err = worktree.Add(".")
-				if err != nil {
-					return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToAddChanges, err)}
+			s.report(models.T("Перекладываю свои сейвы поверх облачных..."))
+			replayed, clashes, err := s.rebaseOnto(repo, worktree, remoteHash)
+			if err != nil {
+				return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRebase), err)}
+			}
+			if len(clashes) > 0 {
+				return models.SyncMsg{
+					Success: false,
+					Message: models.T(models.ErrRebaseClash) + ": " + strings.Join(clashes, ", "),
 				}
+			}
 
-				// Create a conflict resolution commit
-				timestamp := time.Now().Format("2006-01-02 15:04:05")
-				commitMsg := fmt.Sprintf("Auto-resolve conflicts: %s", timestamp)
-
-				_, err = worktree.Commit(commitMsg, &git.CommitOptions{
-					Author: &object.Signature{
-						Name:  models.ConflictAuthorName,
-						Email: models.ConflictAuthorEmail,
-						When:  time.Now(),
-					},
-				})
-				if err != nil {
-					return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToCommit, err)}
-				}
+			syncMsg.Pulled = true
+			syncMsg.Message = fmt.Sprintf(models.T(models.TextRebased), replayed)
+			syncMsg.Received = countNew(repo, remoteHash, before)
+		} else if !s.config.Sync.AutoResolveConflicts {
+			// Without auto-resolve the user decides which side wins
+			return models.ConflictChoiceMsg{
+				Reason:     Explain(pullErr).Error(),
+				Overwrites: s.wouldOverwrite(repo),
+				CanForce:   s.config.Sync.CanForcePush(branchName(repo)),
+			}
+		} else {
+			// Keep local changes over the remote ones
+			if err := s.keepLocalChanges(repo, worktree); err != nil {
+				return models.ErrMsg{Error: err}
 			}
 
 			syncMsg.Conflict = true
-			syncMsg.Message = models.ErrConflictsDetected
+			syncMsg.Message = models.T(models.ErrConflictsDetected)
 		}
 	} else {
 		syncMsg.Pulled = true
-		syncMsg.Message = models.ErrPullSuccess
+		syncMsg.Message = models.T(models.ErrPullSuccess)
+		syncMsg.Received = countNew(repo, headHash(repo), before)
 	}
 
+	// Whatever the remote lacks after the pull is what the push sends
+	outgoing := countNew(repo, headHash(repo), remoteBranchHash(repo, s.config.Sync.Remote))
+
 	// Then, push to remote
-	fmt.Println("Pushing to remote...")
-	pushErr := remote.Push(&git.PushOptions{
-		RemoteName: "origin",
+	s.report(models.T("Отправляю сейвы в облако..."))
+	pushErr := s.withRetry(models.T("Отправляю сейвы"), func() error {
+		return remote.Push(&git.PushOptions{
+			RemoteName: s.config.Sync.Remote,
+		})
 	})
 
 	if pushErr != nil {
 		if pushErr == git.NoErrAlreadyUpToDate {
-			if syncMsg.Message == models.ErrAlreadyUpToDate {
-				syncMsg.Message = models.ErrAlreadyUpToDate
+			if syncMsg.Message == models.T(models.ErrAlreadyUpToDate) {
+				syncMsg.Message = models.T(models.ErrAlreadyUpToDate)
 			} else {
 				syncMsg.Message += ", already up to date on push"
 			}
 			syncMsg.Pushed = false
+		} else if strategy != config.StrategyMerge {
+			// Rebase and ff-only never overwrite the remote
+			return models.SyncMsg{
+				Success:  false,
+				Message:  fmt.Sprintf("%s: %v", models.T(models.ErrPushRejected), Explain(pushErr)),
+				Pulled:   syncMsg.Pulled,
+				Conflict: syncMsg.Conflict,
+			}
+		} else if !s.config.Sync.ForcePush {
+			// Force pushing is opt-in, it would discard what others pushed
+			return models.SyncMsg{
+				Success:  false,
+				Message:  fmt.Sprintf(models.T(models.ErrPushRejectedNewer), s.wouldOverwrite(repo).Affected),
+				Pulled:   syncMsg.Pulled,
+				Conflict: syncMsg.Conflict,
+			}
+		} else if !s.config.Sync.CanForcePush(branchName(repo)) {
+			return models.SyncMsg{
+				Success:  false,
+				Message:  fmt.Sprintf("%s: %v", models.T(models.ErrForcePushForbidden), Explain(pushErr)),
+				Pulled:   syncMsg.Pulled,
+				Conflict: syncMsg.Conflict,
+			}
+		} else if !s.config.Sync.AutoResolveConflicts && !syncMsg.Conflict {
+			// Overwriting the remote is the user's call, like a pull conflict
+			return models.ConflictChoiceMsg{Reason: Explain(pushErr).Error(), Overwrites: s.wouldOverwrite(repo), CanForce: true}
 		} else {
-			// Try force push for simplicity (acceptable for vibecoders)
-			fmt.Println("Normal push failed, trying force push...")
-			forceErr := remote.Push(&git.PushOptions{
-				RemoteName: "origin",
-				Force:      true,
-			})
-
+			// Try force push for simplicity, naming what goes first
+			s.report(fmt.Sprintf(models.T(models.TextForcePushing), s.wouldOverwrite(repo).Affected))
+			summary, forceErr := s.forcePush(repo, remote)
 			if forceErr != nil {
-				return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.ErrFailedToPush, forceErr)}
+				return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToPush), forceErr)}
 			}
 
 			syncMsg.Pushed = true
-			if syncMsg.Message == models.ErrAlreadyUpToDate {
-				syncMsg.Message = models.ErrForcePushSuccess
+			syncMsg.Forced = true
+			syncMsg.Sent = outgoing
+			syncMsg.Summary = summary
+			if syncMsg.Message == models.T(models.ErrAlreadyUpToDate) {
+				syncMsg.Message = models.T(models.ErrForcePushSuccess)
 			} else {
 				syncMsg.Message += ", force pushed successfully"
 			}
+			syncMsg.Message += overwrittenNote(summary)
 		}
 	} else {
 		syncMsg.Pushed = true
-		if syncMsg.Message == models.ErrAlreadyUpToDate {
-			syncMsg.Message = models.ErrPushSuccess
+		syncMsg.Sent = outgoing
+		if syncMsg.Message == models.T(models.ErrAlreadyUpToDate) {
+			syncMsg.Message = models.T(models.ErrPushSuccess)
 		} else {
 			syncMsg.Message += ", pushed successfully"
 		}
 	}
 
+	// Notes ride along when asked, failing to send them doesn't undo the sync
+	if s.config.Sync.PushNotes {
+		if err := s.pushNotes(repo, remote); err != nil {
+			syncMsg.Message += "; " + fmt.Sprintf(models.T(models.TextNotesNotPushed), Explain(err))
+		}
+	}
+
 	return syncMsg
 }
 
 // InitGit initializes a new git repository
 func (s *Service) InitGit() tea.Msg {
 	// Get current directory
-	pwd, err := os.Getwd()
+	pwd, err := s.workDir()
 	if err != nil {
 		return models.ErrMsg{Error: err}
 	}
@@ -381,8 +974,32 @@ func (s *Service) InitGit() tea.Msg {
 	// Initialize git repository
 	_, err = git.PlainInit(pwd, false)
 	if err != nil {
-		return models.ErrMsg{Error: fmt.Errorf("не удалось запустить машину времени: %w", err)}
+		return models.ErrMsg{Error: fmt.Errorf(models.T("не удалось запустить машину времени: %w"), err)}
 	}
 
 	return models.GitInitializedMsg{}
 }
+
+// tagsByCommit maps commit hashes to the names of the tags pointing at them.
+// Annotated tags are peeled to their target commit.
+func tagsByCommit(repo *git.Repository) (map[plumbing.Hash][]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	tags := make(map[plumbing.Hash][]string)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		if tag, err := repo.TagObject(target); err == nil {
+			if tag.TargetType != plumbing.CommitObject {
+				return nil
+			}
+			target = tag.Target
+		}
+		tags[target] = append(tags[target], ref.Name().Short())
+		return nil
+	})
+	return tags, err
+}
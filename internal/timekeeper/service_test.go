@@ -0,0 +1,118 @@
+package timekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+func TestRollbackBackupsWithinOneSecond(t *testing.T) {
+	isolateGitConfig(t)
+	dir := newTestRepo(t, 3, 1)
+	s := newTestService(t, dir)
+	clock := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := first.Parent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backups []string
+	for i := 0; i < 2; i++ {
+		if err := os.WriteFile(filepath.Join(dir, "unsaved.txt"), []byte{byte('a' + i)}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		msg, ok := s.RollbackToCheckpoint(target.Hash.String()).(models.RollbackMsg)
+		if !ok || !msg.Success {
+			t.Fatalf("rollback %d failed: %#v", i+1, msg)
+		}
+		if msg.Backup == "" {
+			t.Fatalf("rollback %d made no backup", i+1)
+		}
+		backups = append(backups, msg.Backup)
+
+		// Only the checkpoint after the target is dropped the first time,
+		// nothing the second, the backups don't count
+		want := 1 - i
+		if msg.Summary == nil || msg.Summary.Affected != want {
+			t.Errorf("rollback %d summary = %+v, want %d dropped", i+1, msg.Summary, want)
+		}
+	}
+
+	if backups[0] == backups[1] {
+		t.Fatalf("both rollbacks backed up to %s", backups[0])
+	}
+	for _, backup := range backups {
+		if _, err := repo.Reference(plumbing.NewBranchReferenceName(backup), false); err != nil {
+			t.Errorf("backup branch %s is gone: %v", backup, err)
+		}
+	}
+}
+
+func TestLoadHistoryByPages(t *testing.T) {
+	isolateGitConfig(t)
+	s := newTestService(t, newTestRepo(t, 7, 1))
+
+	full, ok := s.LoadCheckpoints(0).(models.CheckpointsLoadedMsg)
+	if !ok || len(full.Checkpoints) != 7 || full.HasMore {
+		t.Fatalf("full load = %#v", full)
+	}
+	hashes := func(checkpoints []models.Checkpoint) []string {
+		var out []string
+		for _, checkpoint := range checkpoints {
+			out = append(out, checkpoint.Hash)
+		}
+		return out
+	}
+	want := hashes(full.Checkpoints)
+
+	first, ok := s.LoadCheckpoints(3).(models.CheckpointsLoadedMsg)
+	if !ok || !first.HasMore {
+		t.Fatalf("first page = %#v", first)
+	}
+	got := hashes(first.Checkpoints)
+	for _, more := range []bool{true, false} {
+		page, ok := s.LoadMoreCheckpoints(got[len(got)-1], 3).(models.MoreCheckpointsLoadedMsg)
+		if !ok || page.HasMore != more {
+			t.Fatalf("page after %d = %#v, want more %v", len(got), page, more)
+		}
+		got = append(got, hashes(page.Checkpoints)...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("pages = %v, want %v", got, want)
+	}
+
+	// A page asked for a history reloaded meanwhile still follows its hash
+	if _, ok := s.LoadCheckpoints(3).(models.CheckpointsLoadedMsg); !ok {
+		t.Fatal("reload failed")
+	}
+	page, ok := s.LoadMoreCheckpoints(want[4], 3).(models.MoreCheckpointsLoadedMsg)
+	if !ok || !reflect.DeepEqual(hashes(page.Checkpoints), want[5:]) || page.HasMore {
+		t.Fatalf("page after a reload = %#v, want %v", page, want[5:])
+	}
+	// and leaves the reloaded history's walk alone
+	page, ok = s.LoadMoreCheckpoints(want[2], 3).(models.MoreCheckpointsLoadedMsg)
+	if !ok || !reflect.DeepEqual(hashes(page.Checkpoints), want[3:6]) || !page.HasMore {
+		t.Fatalf("page after the reloaded first page = %#v, want %v", page, want[3:6])
+	}
+}
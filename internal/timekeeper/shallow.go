@@ -0,0 +1,172 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// unshallowDepth is what `git fetch --unshallow` asks for
+const unshallowDepth = 2147483647
+
+// isShallow reports whether the repository is a shallow clone
+func isShallow(repo *git.Repository) bool {
+	shallow, err := repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// historyWalker walks the commits reachable from a start, newest committer
+// time first, one at a time, so a walk can stop and later pick up where it
+// left off. Unlike go-git's log it keeps going when a parent was never
+// downloaded, which is normal in a shallow clone, and reports that the
+// history was cut short instead.
+type historyWalker struct {
+	queue []*object.Commit
+	seen  map[plumbing.Hash]bool
+	// last is the commit next returned last, its parents join the queue on
+	// the following call
+	last      *object.Commit
+	truncated bool
+}
+
+// newHistoryWalker starts a walk at from
+func newHistoryWalker(repo *git.Repository, from plumbing.Hash) (*historyWalker, error) {
+	start, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	return &historyWalker{
+		queue: []*object.Commit{start},
+		seen:  map[plumbing.Hash]bool{from: true},
+	}, nil
+}
+
+// next returns the following commit of the walk, nil once it is over
+func (w *historyWalker) next(repo *git.Repository) (*object.Commit, error) {
+	if err := w.expand(repo); err != nil {
+		return nil, err
+	}
+	if len(w.queue) == 0 {
+		return nil, nil
+	}
+
+	// Pick the newest commit, the queue is as wide as the merges
+	newest := 0
+	for i, commit := range w.queue {
+		if commit.Committer.When.After(w.queue[newest].Committer.When) {
+			newest = i
+		}
+	}
+	commit := w.queue[newest]
+	w.queue = append(w.queue[:newest], w.queue[newest+1:]...)
+	w.last = commit
+	return commit, nil
+}
+
+// more reports whether the walk has commits left
+func (w *historyWalker) more(repo *git.Repository) (bool, error) {
+	if err := w.expand(repo); err != nil {
+		return false, err
+	}
+	return len(w.queue) > 0, nil
+}
+
+// expand queues the parents of the commit next returned last
+func (w *historyWalker) expand(repo *git.Repository) error {
+	if w.last == nil {
+		return nil
+	}
+	for _, parentHash := range w.last.ParentHashes {
+		if w.seen[parentHash] {
+			continue
+		}
+		w.seen[parentHash] = true
+
+		parent, err := repo.CommitObject(parentHash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			w.truncated = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		w.queue = append(w.queue, parent)
+	}
+	w.last = nil
+	return nil
+}
+
+// Unshallow downloads the history a shallow clone left out
+func (s *Service) Unshallow() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	remote, err := repo.Remote(s.config.Sync.Remote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %s", models.T(models.ErrFailedToUnshallow), s.missingRemote(repo))}
+	} else if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnshallow), err)}
+	}
+
+	s.report(models.T("Докачиваю историю..."))
+	err = s.withRetry(models.T("Докачиваю историю"), func() error {
+		return remote.Fetch(&git.FetchOptions{
+			RemoteName: s.config.Sync.Remote,
+			Depth:      unshallowDepth,
+		})
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnshallow), err)}
+	}
+
+	// go-git doesn't rewrite the shallow list itself, drop the commits whose
+	// parents have arrived
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnshallow), err)}
+	}
+	var remaining []plumbing.Hash
+	for _, hash := range shallow {
+		if !parentsPresent(repo, hash) {
+			remaining = append(remaining, hash)
+		}
+	}
+	if err := repo.Storer.SetShallow(remaining); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnshallow), err)}
+	}
+
+	if len(remaining) > 0 {
+		return models.StatusMsg{Text: models.T(models.TextStillShallow)}
+	}
+	return models.StatusMsg{Text: models.T(models.TextUnshallowed)}
+}
+
+// parentsPresent reports whether all parents of the commit are available
+func parentsPresent(repo *git.Repository, hash plumbing.Hash) bool {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return false
+	}
+	for _, parent := range commit.ParentHashes {
+		if _, err := repo.CommitObject(parent); err != nil {
+			return false
+		}
+	}
+	return true
+}
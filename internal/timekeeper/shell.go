@@ -0,0 +1,43 @@
+package timekeeper
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ShellCommand builds the command for the drop-to-shell escape hatch: the
+// configured shell.command, or the user's interactive shell, started in the
+// repository directory
+func (s *Service) ShellCommand() (*exec.Cmd, error) {
+	pwd, err := s.workDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case s.config.Shell.Command != "" && runtime.GOOS == "windows":
+		cmd = exec.Command("cmd", "/C", s.config.Shell.Command)
+	case s.config.Shell.Command != "":
+		cmd = exec.Command("sh", "-c", s.config.Shell.Command)
+	default:
+		cmd = exec.Command(interactiveShell())
+	}
+	cmd.Dir = pwd
+	return cmd, nil
+}
+
+// interactiveShell picks the user's shell with a per-platform fallback
+func interactiveShell() string {
+	if runtime.GOOS == "windows" {
+		if shell := os.Getenv("COMSPEC"); shell != "" {
+			return shell
+		}
+		return "cmd"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "sh"
+}
@@ -0,0 +1,161 @@
+package timekeeper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// LoadSquashCandidates finds the tool checkpoints at the tip of the branch
+// that can be squashed into one commit
+func (s *Service) LoadSquashCandidates() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	run, err := toolCheckpointRun(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSquash), err)}
+	}
+
+	messages := make([]string, 0, len(run))
+	for _, commit := range run {
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		messages = append(messages, subject)
+	}
+
+	return models.SquashCandidatesMsg{Messages: messages}
+}
+
+// SquashToolCheckpoints collapses the tool checkpoints made since the last
+// manual commit into a single commit with the given message
+func (s *Service) SquashToolCheckpoints(message string) (msg tea.Msg) {
+	defer func() { s.record(models.ActivitySquash, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	run, err := toolCheckpointRun(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSquash), err)}
+	}
+	if len(run) < 2 {
+		return models.CheckpointCreatedMsg{
+			Success: false,
+			Message: models.T(models.TextNothingToSquash),
+		}
+	}
+
+	// The run is ordered from the tip down, its oldest commit's parent is the base
+	tip, oldest := run[0], run[len(run)-1]
+	var base []plumbing.Hash
+	if oldest.NumParents() > 0 {
+		base = oldest.ParentHashes[:1]
+	}
+
+	author := s.signature(repo, models.CheckpointAuthorName, models.CheckpointAuthorEmail)
+	hash, err := squashCommits(repo, base, tip, message, author)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSquash), err)}
+	}
+
+	return models.StatusMsg{
+		Text:    fmt.Sprintf(models.T("Схлопнуто сейвов: %d → %.7s"), len(run), hash.String()),
+		Summary: summarize(repo, models.T(models.TitleSummarySquash), tip.Hash, hash),
+	}
+}
+
+// toolCheckpointRun returns the contiguous tool-authored commits at HEAD,
+// newest first. Merge commits and pinned checkpoints end the run, the pin
+// has to be removed to squash past it.
+func toolCheckpointRun(repo *git.Repository) ([]*object.Commit, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := pinnedCommits(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	var run []*object.Commit
+	for isToolCheckpoint(commit) && commit.NumParents() <= 1 && !pins[commit.Hash] {
+		run = append(run, commit)
+		if commit.NumParents() == 0 {
+			break
+		}
+		if commit, err = commit.Parent(0); err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+// squashCommits writes a commit with tip's tree on top of parents and moves
+// HEAD to it. The worktree and index already match tip, so they stay as is.
+func squashCommits(repo *git.Repository, parents []plumbing.Hash, tip *object.Commit, message string, author *object.Signature) (plumbing.Hash, error) {
+	commit := &object.Commit{
+		Author:       *author,
+		Committer:    *author,
+		Message:      message,
+		TreeHash:     tip.TreeHash,
+		ParentHashes: parents,
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return hash, moveHead(repo, hash)
+}
+
+// moveHead points the current branch (or a detached HEAD) at hash
+func moveHead(repo *git.Repository, hash plumbing.Hash) error {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return err
+	}
+
+	name := plumbing.HEAD
+	if head.Type() == plumbing.SymbolicReference {
+		name = head.Target()
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(name, hash))
+}
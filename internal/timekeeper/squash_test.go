@@ -0,0 +1,20 @@
+package timekeeper
+
+import (
+	"testing"
+
+	"time-machine/internal/models"
+)
+
+func TestSquashNothingIsAWarning(t *testing.T) {
+	isolateGitConfig(t)
+	s := newTestService(t, newTestRepo(t, 2, 1))
+
+	msg, ok := s.SquashToolCheckpoints("squashed").(models.CheckpointCreatedMsg)
+	if !ok || msg.Success {
+		t.Fatalf("squashing manual commits only = %#v, want a failed checkpoint", msg)
+	}
+	if msg.Message != models.T(models.TextNothingToSquash) {
+		t.Errorf("message = %q, want %q", msg.Message, models.T(models.TextNothingToSquash))
+	}
+}
@@ -0,0 +1,355 @@
+package timekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"time-machine/internal/models"
+)
+
+// StagePaths adds the given files to the next checkpoint
+func (s *Service) StagePaths(paths []string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// Get worktree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	if err := stagePaths(worktree, paths); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToStage), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T("Добавлено в сейв файлов: %d"), len(paths))}
+}
+
+// UnstagePaths removes the given files from the next checkpoint
+func (s *Service) UnstagePaths(paths []string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	if err := unstagePaths(repo, paths); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnstage), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T("Убрано из сейва файлов: %d"), len(paths))}
+}
+
+// Unstage removes a single file from the next checkpoint
+func (s *Service) Unstage(path string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	if err := unstagePaths(repo, []string{path}); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnstage), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T("Убрано из сейва: %s"), path)}
+}
+
+// UnstageAll removes every staged file from the next checkpoint
+func (s *Service) UnstageAll() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// Get worktree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+
+	var paths []string
+	for file, entry := range status {
+		if isStaged(entry) {
+			paths = append(paths, file)
+		}
+	}
+
+	if err := unstagePaths(repo, paths); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUnstage), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T("Убрано из сейва файлов: %d"), len(paths))}
+}
+
+// RestoreDeletedFile brings back a deleted file as it is in HEAD, both in
+// the working tree and in the index
+func (s *Service) RestoreDeletedFile(path string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// Get worktree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRestoreFile), err)}
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRestoreFile), err)}
+	}
+	file, err := commit.File(path)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrFileNotInCheckpoint), path, head.Hash().String())}
+	}
+
+	if err := (stashFile{path: path, file: file}).restore(worktree.Filesystem.Root()); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRestoreFile), err)}
+	}
+	// A deletion already staged with `git rm` is undone as well
+	if err := unstagePaths(repo, []string{path}); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToRestoreFile), err)}
+	}
+
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextFileRestored), path)}
+}
+
+// isStaged reports whether the file has changes recorded in the index
+func isStaged(entry *git.FileStatus) bool {
+	return entry.Staging != git.Unmodified && entry.Staging != git.Untracked
+}
+
+// pendingFileCount counts the files a checkpoint made with opts would
+// take, before anything is staged
+func pendingFileCount(worktree *git.Worktree, opts CheckpointOptions) (int, error) {
+	if opts.Paths != nil && !opts.StagedOnly {
+		return len(opts.Paths), nil
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range status {
+		switch {
+		case opts.StagedOnly:
+			if isStaged(entry) {
+				count++
+			}
+		case entry.Worktree == git.Untracked:
+			if !opts.SkipUntracked {
+				count++
+			}
+		case entry.Staging != git.Unmodified || entry.Worktree != git.Unmodified:
+			count++
+		}
+	}
+	return count, nil
+}
+
+// hasStagedChanges reports whether the index differs from HEAD
+func hasStagedChanges(status git.Status) bool {
+	for _, entry := range status {
+		if isStaged(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// stagePaths records the worktree state of the given paths in the index.
+// Deleted files are removed from the index.
+func stagePaths(worktree *git.Worktree, paths []string) error {
+	for _, path := range paths {
+		err := worktree.AddWithOptions(&git.AddOptions{Path: path, SkipStatus: true})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTracked stages the changes to files git already tracks, leaving new
+// files out
+func addTracked(worktree *git.Worktree) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for file, entry := range status {
+		if entry.Worktree != git.Unmodified && entry.Worktree != git.Untracked {
+			paths = append(paths, file)
+		}
+	}
+	return stagePaths(worktree, paths)
+}
+
+// scopeIndex makes the index contain exactly the given changed paths:
+// they get staged and every other staged change is reset to HEAD.
+func scopeIndex(repo *git.Repository, worktree *git.Worktree, paths []string) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+
+	include := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		include[path] = true
+	}
+
+	var exclude []string
+	for file, entry := range status {
+		if isStaged(entry) && !include[file] {
+			exclude = append(exclude, file)
+		}
+	}
+
+	if err := unstagePaths(repo, exclude); err != nil {
+		return err
+	}
+	return stagePaths(worktree, paths)
+}
+
+// unstagePaths resets the index entries of the given paths to their HEAD state.
+// Paths that don't exist in HEAD are dropped from the index entirely.
+func unstagePaths(repo *git.Repository, paths []string) error {
+	var tree *object.Tree
+	head, err := repo.Head()
+	switch err {
+	case nil:
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return err
+		}
+		if tree, err = commit.Tree(); err != nil {
+			return err
+		}
+	case plumbing.ErrReferenceNotFound:
+		// No commits yet, everything in the index is new
+	default:
+		return err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		var headEntry *object.TreeEntry
+		if tree != nil {
+			headEntry, err = tree.FindEntry(path)
+			if err != nil && err != object.ErrEntryNotFound && err != object.ErrDirectoryNotFound {
+				return err
+			}
+		}
+
+		if headEntry == nil {
+			if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+				return err
+			}
+			continue
+		}
+
+		entry, err := idx.Entry(path)
+		if err == index.ErrEntryNotFound {
+			// Staged deletion, bring the entry back
+			entry = idx.Add(path)
+		} else if err != nil {
+			return err
+		}
+		// Drop cached stat info so git re-hashes the worktree file
+		*entry = index.Entry{
+			Name: entry.Name,
+			Hash: headEntry.Hash,
+			Mode: headEntry.Mode,
+		}
+	}
+
+	return repo.Storer.SetIndex(idx)
+}
+
+// EstimateSize sums the on-disk sizes of the given files, a rough idea of
+// how much a checkpoint of them adds to the repository. Deleted files and
+// anything that can't be read count as zero.
+func (s *Service) EstimateSize(paths []string) int64 {
+	pwd, err := s.workDir()
+	if err != nil {
+		return 0
+	}
+
+	// Paths are relative to the root, the app may be in a subdirectory
+	root := RepositoryRoot(pwd)
+
+	var total int64
+	for _, path := range paths {
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(path)))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		total += info.Size()
+	}
+	return total
+}
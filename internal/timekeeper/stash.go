@@ -0,0 +1,373 @@
+package timekeeper
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"time-machine/internal/models"
+)
+
+// stashRef is the ref whose reflog holds the stash entries
+const stashRef = "refs/stash"
+
+// errNoDotGit is returned when the repository isn't stored on disk
+var errNoDotGit = errors.New("repository storage has no filesystem")
+
+// stashLogLine is one line of the refs/stash reflog
+type stashLogLine struct {
+	old, new plumbing.Hash
+	// who is "Name <email> timestamp tz", kept verbatim for rewriting
+	who     string
+	when    time.Time
+	message string
+}
+
+// ListStashes returns the stash entries, stash@{0} first
+func (s *Service) ListStashes() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	lines, err := readStashLog(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToReadStash), err)}
+	}
+
+	entries := make([]models.StashEntry, 0, len(lines))
+	for i := len(lines) - 1; i >= 0; i-- {
+		entries = append(entries, models.StashEntry{
+			Index:   len(entries),
+			Hash:    lines[i].new.String(),
+			Message: lines[i].message,
+			Date:    lines[i].when,
+		})
+	}
+	return models.StashesLoadedMsg{Entries: entries}
+}
+
+// DropStash deletes stash@{index}
+func (s *Service) DropStash(index int) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	if err := dropStash(repo, index); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToDropStash), err)}
+	}
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextStashDropped), index)}
+}
+
+// ApplyStash restores the changes of stash@{index} into the working tree,
+// dropping the entry afterwards when pop is set. Like `git stash apply`
+// without --index, the staged state isn't restored. Files that have local
+// changes are never overwritten, the apply is refused instead.
+func (s *Service) ApplyStash(index int, pop bool) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	lines, err := readStashLog(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToReadStash), err)}
+	}
+	if index < 0 || index >= len(lines) {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrStashNotFound), index)}
+	}
+
+	stash, err := repo.CommitObject(lines[len(lines)-1-index].new)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToApplyStash), err)}
+	}
+
+	files, err := stashFiles(stash)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToApplyStash), err)}
+	}
+
+	// Refuse rather than clobber anything the user is working on
+	status, err := worktree.Status()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetStatus), err)}
+	}
+	var busy []string
+	for _, file := range files {
+		if entry, ok := status[file.path]; ok && (entry.Staging != git.Unmodified || entry.Worktree != git.Unmodified) {
+			busy = append(busy, file.path)
+		}
+	}
+	if len(busy) > 0 {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %s", models.T(models.ErrStashWouldOverwrite), strings.Join(busy, ", "))}
+	}
+
+	root := worktree.Filesystem.Root()
+	for _, file := range files {
+		if err := file.restore(root); err != nil {
+			return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToApplyStash), err)}
+		}
+	}
+
+	if !pop {
+		return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextStashApplied), index)}
+	}
+	if err := dropStash(repo, index); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToDropStash), err)}
+	}
+	return models.StatusMsg{Text: fmt.Sprintf(models.T(models.TextStashPopped), index)}
+}
+
+// stashFile is a working tree change recorded in a stash
+type stashFile struct {
+	path string
+	// file is nil when the stash deleted the path
+	file *object.File
+}
+
+// restore writes the stashed version of the file into the working tree
+func (f stashFile) restore(root string) error {
+	target := filepath.Join(root, filepath.FromSlash(f.path))
+	if f.file == nil {
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	content, err := f.file.Contents()
+	if err != nil {
+		return err
+	}
+
+	if f.file.Mode == filemode.Symlink {
+		os.Remove(target)
+		return os.Symlink(content, target)
+	}
+	perm := os.FileMode(0o644)
+	if f.file.Mode == filemode.Executable {
+		perm = 0o755
+	}
+	return os.WriteFile(target, []byte(content), perm)
+}
+
+// stashFiles lists what a stash changed relative to the commit it was made
+// on, plus the untracked files it kept in its third parent
+func stashFiles(stash *object.Commit) ([]stashFile, error) {
+	if stash.NumParents() < 2 {
+		return nil, errors.New(models.T(models.ErrNotAStash))
+	}
+
+	base, err := stash.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := stash.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(baseTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []stashFile
+	for _, change := range changes {
+		if change.To.Name == "" {
+			files = append(files, stashFile{path: change.From.Name})
+			continue
+		}
+		file, err := tree.TreeEntryFile(&change.To.TreeEntry)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, stashFile{path: change.To.Name, file: file})
+	}
+
+	// `git stash -u` keeps untracked files in a parentless third commit
+	if stash.NumParents() > 2 {
+		untracked, err := stash.Parent(2)
+		if err != nil {
+			return nil, err
+		}
+		untrackedTree, err := untracked.Tree()
+		if err != nil {
+			return nil, err
+		}
+		err = untrackedTree.Files().ForEach(func(file *object.File) error {
+			files = append(files, stashFile{path: file.Name, file: file})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// dotGit returns the filesystem of the repository's .git directory
+func dotGit(repo *git.Repository) (billy.Filesystem, error) {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errNoDotGit
+	}
+	return storage.Filesystem(), nil
+}
+
+// readStashLog parses the refs/stash reflog, oldest entry first. go-git
+// has no reflog support, so the file is read directly.
+func readStashLog(repo *git.Repository) ([]stashLogLine, error) {
+	fs, err := dotGit(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fs.Open(filepath.Join("logs", stashRef))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []stashLogLine
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line, ok := parseReflogLine(scanner.Text()); ok {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseReflogLine parses "<old> <new> Name <email> <unix> <tz>\t<message>"
+func parseReflogLine(text string) (stashLogLine, bool) {
+	header, message, _ := strings.Cut(text, "\t")
+	fields := strings.SplitN(header, " ", 3)
+	if len(fields) < 3 {
+		return stashLogLine{}, false
+	}
+
+	line := stashLogLine{
+		old:     plumbing.NewHash(fields[0]),
+		new:     plumbing.NewHash(fields[1]),
+		who:     fields[2],
+		message: message,
+	}
+
+	// The timestamp is the second to last field of the identity
+	if parts := strings.Fields(line.who); len(parts) >= 2 {
+		if seconds, err := strconv.ParseInt(parts[len(parts)-2], 10, 64); err == nil {
+			line.when = time.Unix(seconds, 0)
+		}
+	}
+	return line, true
+}
+
+// dropStash removes stash@{index} from the reflog and points refs/stash at
+// the newest remaining entry, deleting both when nothing is left
+func dropStash(repo *git.Repository, index int) error {
+	lines, err := readStashLog(repo)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(lines) {
+		return fmt.Errorf(models.T(models.ErrStashNotFound), index)
+	}
+
+	position := len(lines) - 1 - index
+	lines = append(lines[:position], lines[position+1:]...)
+
+	fs, err := dotGit(repo)
+	if err != nil {
+		return err
+	}
+	logPath := filepath.Join("logs", stashRef)
+
+	if len(lines) == 0 {
+		if err := fs.Remove(logPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		err := repo.Storer.RemoveReference(plumbing.ReferenceName(stashRef))
+		if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	// Keep the chain of old/new ids consistent like `git reflog delete --rewrite`
+	var buf bytes.Buffer
+	previous := plumbing.ZeroHash
+	for _, line := range lines {
+		fmt.Fprintf(&buf, "%s %s %s\t%s\n", previous, line.new, line.who, line.message)
+		previous = line.new
+	}
+
+	file, err := fs.Create(logPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, &buf); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(stashRef), previous))
+}
@@ -0,0 +1,53 @@
+package timekeeper
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// CheckpointStats counts the lines added and deleted by each of the
+// checkpoints hashes against its first parent. Diffing is costly, so the
+// history asks only for the entries on screen.
+func (s *Service) CheckpointStats(hashes []string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	stats := make(map[string]models.DiffStat, len(hashes))
+	for _, hash := range hashes {
+		// A checkpoint that can't be diffed, like the edge of a shallow
+		// clone, gets an empty stat so it isn't asked for again
+		stats[hash] = models.DiffStat{}
+
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			continue
+		}
+		fileStats, err := commit.Stats()
+		if err != nil {
+			continue
+		}
+
+		var stat models.DiffStat
+		for _, file := range fileStats {
+			stat.Added += file.Addition
+			stat.Deleted += file.Deletion
+		}
+		stats[hash] = stat
+	}
+
+	return models.CheckpointStatsMsg{Stats: stats}
+}
@@ -0,0 +1,72 @@
+package timekeeper
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// loadSubmodules collects the submodules of the worktree with their state.
+// Errors are ignored: submodule info is purely informational for the status.
+func loadSubmodules(worktree *git.Worktree) []models.SubmoduleInfo {
+	submodules, err := worktree.Submodules()
+	if err != nil || len(submodules) == 0 {
+		return nil
+	}
+
+	var infos []models.SubmoduleInfo
+	for _, sub := range submodules {
+		info := models.SubmoduleInfo{Path: sub.Config().Path}
+
+		status, err := sub.Status()
+		if err == nil {
+			info.Initialized = status.Current != plumbing.ZeroHash
+			info.Clean = status.IsClean()
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// UpdateSubmodules initializes and updates all submodules to the recorded commits
+func (s *Service) UpdateSubmodules() tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	// Get worktree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUpdateSubmodules), err)}
+	}
+
+	err = submodules.Update(&git.SubmoduleUpdateOptions{
+		Init:              true,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+	})
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUpdateSubmodules), err)}
+	}
+
+	return models.StatusMsg{Text: models.T(models.TextSubmodulesUpdated)}
+}
@@ -0,0 +1,158 @@
+package timekeeper
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"time-machine/internal/models"
+)
+
+// summaryCommitLimit caps how many affected commits a summary lists
+const summaryCommitLimit = 10
+
+// summarize recaps a destructive operation that moved a ref from oldHead to
+// newHead, listing the commits that are no longer reachable from it
+func summarize(repo *git.Repository, title string, oldHead, newHead plumbing.Hash) *models.OperationSummary {
+	summary := &models.OperationSummary{
+		Title:   title,
+		OldHead: oldHead.String(),
+		NewHead: newHead.String(),
+	}
+	if oldHead.IsZero() || oldHead == newHead {
+		return summary
+	}
+
+	newCommit, err := repo.CommitObject(newHead)
+	if err != nil {
+		return summary
+	}
+	iter, err := repo.Log(&git.LogOptions{From: oldHead})
+	if err != nil {
+		return summary
+	}
+	defer iter.Close()
+
+	// Walk back from the old head until the history joins the new one
+	_ = iter.ForEach(func(commit *object.Commit) error {
+		if isAncestor, err := commit.IsAncestor(newCommit); err != nil || isAncestor {
+			return storer.ErrStop
+		}
+		summary.Affected++
+		if len(summary.Commits) < summaryCommitLimit {
+			summary.Commits = append(summary.Commits, fmt.Sprintf("%.7s %s", commit.Hash, firstLine(commit.Message)))
+		}
+		return nil
+	})
+	return summary
+}
+
+// countNew counts the commits reachable from tip that base doesn't contain,
+// all of them when base is unknown
+func countNew(repo *git.Repository, tip, base plumbing.Hash) int {
+	if tip.IsZero() || tip == base {
+		return 0
+	}
+
+	var baseCommit *object.Commit
+	if !base.IsZero() {
+		baseCommit, _ = repo.CommitObject(base)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: tip})
+	if err != nil {
+		return 0
+	}
+	defer iter.Close()
+
+	count := 0
+	_ = iter.ForEach(func(commit *object.Commit) error {
+		if baseCommit != nil {
+			if isAncestor, err := commit.IsAncestor(baseCommit); err != nil || isAncestor {
+				return storer.ErrStop
+			}
+		}
+		count++
+		return nil
+	})
+	return count
+}
+
+// headHash returns the commit HEAD points to, the zero hash when unborn
+func headHash(repo *git.Repository) plumbing.Hash {
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return head.Hash()
+}
+
+// forcePushSummary recaps a force push that replaced the remote branch
+// oldRemote with the local HEAD
+func forcePushSummary(repo *git.Repository, oldRemote plumbing.Hash) *models.OperationSummary {
+	head, err := repo.Head()
+	if err != nil {
+		return nil
+	}
+	return summarize(repo, models.T(models.TitleSummaryForcePush), oldRemote, head.Hash())
+}
+
+// remoteBranchHash returns what the remote-tracking ref of the current
+// branch points to, the zero hash when it is unknown
+func remoteBranchHash(repo *git.Repository, remoteName string) plumbing.Hash {
+	branch := branchName(repo)
+	if branch == "" {
+		return plumbing.ZeroHash
+	}
+	ref, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), true)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return ref.Hash()
+}
+
+// isToolCheckpoint reports whether the tool made commit. Checkpoints are
+// committed under the tool identity, older ones were authored by it too.
+func isToolCheckpoint(commit *object.Commit) bool {
+	return commit.Committer.Email == models.CheckpointAuthorEmail || commit.Author.Email == models.CheckpointAuthorEmail
+}
+
+// unpushedCheckpoints counts the tool-made checkpoints between head and its
+// merge base with the remote branch. Without a remote branch there is
+// nothing to compare with, so nothing is counted.
+func unpushedCheckpoints(repo *git.Repository, head, remote plumbing.Hash) int {
+	if remote.IsZero() || head == remote {
+		return 0
+	}
+	headCommit, err := repo.CommitObject(head)
+	if err != nil {
+		return 0
+	}
+	remoteCommit, err := repo.CommitObject(remote)
+	if err != nil {
+		return 0
+	}
+	bases, err := headCommit.MergeBase(remoteCommit)
+	if err != nil {
+		return 0
+	}
+
+	ignore := make([]plumbing.Hash, 0, len(bases))
+	for _, base := range bases {
+		ignore = append(ignore, base.Hash)
+	}
+
+	count := 0
+	iter := object.NewCommitPreorderIter(headCommit, nil, ignore)
+	defer iter.Close()
+	_ = iter.ForEach(func(commit *object.Commit) error {
+		if isToolCheckpoint(commit) {
+			count++
+		}
+		return nil
+	})
+	return count
+}
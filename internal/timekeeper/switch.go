@@ -0,0 +1,64 @@
+package timekeeper
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+
+	"time-machine/internal/config"
+	"time-machine/internal/models"
+)
+
+// RepositoryRoot returns the top directory of the repository the service
+// works in
+func (s *Service) RepositoryRoot() (string, error) {
+	pwd, err := s.workDir()
+	if err != nil {
+		return "", err
+	}
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return "", err
+	}
+	defer s.releaseRepository(repo)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return worktree.Filesystem.Root(), nil
+}
+
+// SwitchRepository checks that the repository at path opens. It runs as a
+// command, alongside others reading the directory and config of the
+// service, so the switch itself is left to UseRepository.
+func (s *Service) SwitchRepository(path string) tea.Msg {
+	if _, err := openRepository(path); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSwitchRepo), err)}
+	}
+	return models.RepositoryOpenedMsg{Path: path}
+}
+
+// UseRepository makes the service work in the repository at path, with
+// that repository's config, and remembers it among the recent ones. Call
+// it from Update, where no command is changing the service at the same time.
+func (s *Service) UseRepository(path string) tea.Msg {
+	if err := s.SetDir(path); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToSwitchRepo), err)}
+	}
+
+	// A broken config falls back to the defaults, like on start
+	cfg, cfgErr := config.Load(RepositoryRoot(path))
+	s.base = cfg
+	s.config = cfg
+	if profiled, err := cfg.WithProfile(cfg.Profile); err == nil {
+		s.config = profiled
+	}
+
+	_ = config.RememberRecent(path)
+
+	return models.RepositorySwitchedMsg{
+		Path:      path,
+		Profile:   s.config.Profile,
+		ConfigErr: cfgErr,
+	}
+}
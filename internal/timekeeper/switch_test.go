@@ -0,0 +1,33 @@
+package timekeeper
+
+import (
+	"testing"
+
+	"time-machine/internal/models"
+)
+
+func TestSwitchRepositoryLeavesServiceToUpdate(t *testing.T) {
+	isolateGitConfig(t)
+	current := newTestRepo(t, 1, 1)
+	other := newTestRepo(t, 1, 1)
+	s := newTestService(t, current)
+
+	opened, ok := s.SwitchRepository(other).(models.RepositoryOpenedMsg)
+	if !ok {
+		t.Fatalf("SwitchRepository didn't open %s", other)
+	}
+	if s.dir != current {
+		t.Fatalf("the command switched the service to %s", s.dir)
+	}
+
+	if _, ok := s.UseRepository(opened.Path).(models.RepositorySwitchedMsg); !ok {
+		t.Fatalf("UseRepository didn't switch to %s", opened.Path)
+	}
+	if s.dir != other {
+		t.Errorf("the service works in %s, want %s", s.dir, other)
+	}
+
+	if _, ok := s.SwitchRepository(t.TempDir()).(models.ErrMsg); !ok {
+		t.Error("a directory without a repository opened")
+	}
+}
@@ -0,0 +1,47 @@
+package timekeeper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// TagCheckpoint marks the checkpoint hash with a lightweight git tag named
+// tagName, so it stands out in the history and can be found later. An
+// existing tag is never moved.
+func (s *Service) TagCheckpoint(hash, tagName string) tea.Msg {
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	if err := plumbing.NewTagReferenceName(tagName).Validate(); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrInvalidTagName), tagName)}
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToTag), err)}
+	}
+
+	if _, err := repo.CreateTag(tagName, commit.Hash, nil); errors.Is(err, git.ErrTagExists) {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrTagExists), tagName)}
+	} else if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToTag), err)}
+	}
+
+	return models.TaggedMsg{Hash: commit.Hash.String(), Tag: tagName}
+}
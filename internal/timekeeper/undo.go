@@ -0,0 +1,73 @@
+package timekeeper
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"time-machine/internal/models"
+)
+
+// UndoLastCheckpoint takes back the last checkpoint like `git reset --soft
+// HEAD~1`: the branch steps back to its parent while the files and the
+// index keep everything the checkpoint had, ready to be saved again.
+func (s *Service) UndoLastCheckpoint() (msg tea.Msg) {
+	defer func() { s.record(models.ActivityUndo, msg) }()
+
+	// Get current directory
+	pwd, err := s.workDir()
+	if err != nil {
+		return models.ErrMsg{Error: err}
+	}
+
+	// Open git repository
+	repo, err := s.openRepository(pwd)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToOpenRepo), err)}
+	}
+	defer s.releaseRepository(repo)
+
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return models.StatusMsg{Text: models.T(models.TextNothingToUndo)}
+	}
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetHead), err)}
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUndo), err)}
+	}
+	// The very first checkpoint has nothing before it to step back to
+	if commit.NumParents() == 0 {
+		return models.StatusMsg{Text: models.T(models.TextNothingToUndo)}
+	}
+
+	// Like squashing, undoing never drops a pinned checkpoint, the pin has
+	// to be removed first
+	pins, err := pinnedCommits(repo)
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUndo), err)}
+	}
+	if pins[commit.Hash] {
+		return models.ErrMsg{Error: fmt.Errorf(models.T(models.ErrUndoPinned), commit.Hash.String())}
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToGetWorktree), err)}
+	}
+
+	parent := commit.ParentHashes[0]
+	if err := worktree.Reset(&git.ResetOptions{Commit: parent, Mode: git.SoftReset}); err != nil {
+		return models.ErrMsg{Error: fmt.Errorf("%s: %w", models.T(models.ErrFailedToUndo), err)}
+	}
+
+	return models.StatusMsg{
+		Text:    fmt.Sprintf(models.T(models.TextCheckpointUndone), firstLine(commit.Message)),
+		Summary: summarize(repo, models.T(models.TitleSummaryUndo), head.Hash(), parent),
+	}
+}
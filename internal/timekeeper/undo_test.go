@@ -0,0 +1,42 @@
+package timekeeper
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	"time-machine/internal/models"
+)
+
+func TestUndoKeepsPinnedCheckpoint(t *testing.T) {
+	isolateGitConfig(t)
+	dir := newTestRepo(t, 2, 1)
+	s := newTestService(t, dir)
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip := headHash(repo)
+
+	if msg, ok := s.PinCheckpoint(tip.String()).(models.PinnedMsg); !ok || !msg.Pinned {
+		t.Fatalf("pinning failed: %#v", msg)
+	}
+	if _, ok := s.UndoLastCheckpoint().(models.ErrMsg); !ok {
+		t.Error("undo dropped a pinned checkpoint")
+	}
+	if got := headHash(repo); got != tip {
+		t.Fatalf("HEAD moved to %s", got)
+	}
+
+	// Once unpinned it goes like any other
+	if msg, ok := s.UnpinCheckpoint(tip.String()).(models.PinnedMsg); !ok || msg.Pinned {
+		t.Fatalf("unpinning failed: %#v", msg)
+	}
+	if _, ok := s.UndoLastCheckpoint().(models.StatusMsg); !ok {
+		t.Fatal("undo failed")
+	}
+	if got := headHash(repo); got == tip {
+		t.Error("HEAD still at the undone checkpoint")
+	}
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"time-machine/internal/models"
+)
+
+// fit wraps s to the terminal width and cuts it to the terminal height.
+// The last line is the help, so it stays and the middle gives way instead.
+// A zero size means the terminal hasn't reported it yet.
+func fit(s string, width, height int) string {
+	if width > 0 {
+		s = ansi.Wrap(s, width, "")
+	}
+	if height <= 0 {
+		return s
+	}
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= height {
+		return s
+	}
+	if height == 1 {
+		return lines[0]
+	}
+	return strings.Join(append(lines[:height-1], lines[len(lines)-1]), "\n")
+}
+
+// relativeTime formats t relative to now, e.g. "5 минут назад"
+func relativeTime(t, now time.Time) string {
+	elapsed := now.Sub(t)
+	switch {
+	case elapsed < time.Minute:
+		return models.T("только что")
+	case elapsed < time.Hour:
+		minutes := int(elapsed.Minutes())
+		return fmt.Sprintf(models.T("%d %s назад"), minutes, plural(minutes, "минуту", "минуты", "минут"))
+	case elapsed < 24*time.Hour:
+		hours := int(elapsed.Hours())
+		return fmt.Sprintf(models.T("%d %s назад"), hours, plural(hours, "час", "часа", "часов"))
+	case elapsed < 48*time.Hour:
+		return models.T("вчера")
+	case elapsed < 30*24*time.Hour:
+		days := int(elapsed.Hours() / 24)
+		return fmt.Sprintf(models.T("%d %s назад"), days, plural(days, "день", "дня", "дней"))
+	case elapsed < 365*24*time.Hour:
+		months := int(elapsed.Hours() / 24 / 30)
+		return fmt.Sprintf(models.T("%d %s назад"), months, plural(months, "месяц", "месяца", "месяцев"))
+	default:
+		years := int(elapsed.Hours() / 24 / 365)
+		return fmt.Sprintf(models.T("%d %s назад"), years, plural(years, "год", "года", "лет"))
+	}
+}
+
+// historyDate formats the date of a checkpoint in the history: relative
+// when relative is on and the checkpoint is younger than limit, the full
+// date otherwise. A zero limit makes every date relative.
+func historyDate(date, now time.Time, relative bool, limit time.Duration) string {
+	if relative && (limit == 0 || now.Sub(date) < limit) {
+		return relativeTime(date, now)
+	}
+	return date.Format("2006-01-02 15:04")
+}
+
+// formatSize formats a byte count, e.g. "2.3 МБ"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf(models.T("%d Б"), bytes)
+	}
+	units := []string{models.T("КБ"), models.T("МБ"), models.T("ГБ"), models.T("ТБ")}
+	value := float64(bytes) / unit
+	i := 0
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// plural picks the Russian word form for n: one (1, 21), few (2-4, 22-24) or
+// many. English only tells one from many.
+func plural(n int, one, few, many string) string {
+	if models.Language == models.LanguageEnglish {
+		if n == 1 {
+			return models.T(one)
+		}
+		return models.T(many)
+	}
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	default:
+		return many
+	}
+}
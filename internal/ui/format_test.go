@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"time-machine/internal/models"
+)
+
+// useRussian runs the test with the Russian texts whatever the locale
+func useRussian(t *testing.T) {
+	t.Helper()
+	language := models.Language
+	models.Language = models.LanguageRussian
+	t.Cleanup(func() { models.Language = language })
+}
+
+func TestRelativeTime(t *testing.T) {
+	useRussian(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{0, "только что"},
+		{59 * time.Second, "только что"},
+		{time.Minute, "1 минуту назад"},
+		{2 * time.Minute, "2 минуты назад"},
+		{5 * time.Minute, "5 минут назад"},
+		{21 * time.Minute, "21 минуту назад"},
+		{59*time.Minute + 59*time.Second, "59 минут назад"},
+		{time.Hour, "1 час назад"},
+		{3 * time.Hour, "3 часа назад"},
+		{11 * time.Hour, "11 часов назад"},
+		{23*time.Hour + 59*time.Minute, "23 часа назад"},
+		{24 * time.Hour, "вчера"},
+		{47 * time.Hour, "вчера"},
+		{48 * time.Hour, "2 дня назад"},
+		{6 * 24 * time.Hour, "6 дней назад"},
+		{7 * 24 * time.Hour, "7 дней назад"},
+		{29 * 24 * time.Hour, "29 дней назад"},
+		{30 * 24 * time.Hour, "1 месяц назад"},
+		{400 * 24 * time.Hour, "1 год назад"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("relativeTime(now - %v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestHistoryDate(t *testing.T) {
+	useRussian(t)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		ago      time.Duration
+		relative bool
+		limit    time.Duration
+		want     string
+	}{
+		{"recent", 2 * time.Hour, true, week, "2 часа назад"},
+		{"just under a week", week - time.Minute, true, week, "6 дней назад"},
+		{"a week old", week, true, week, "2024-03-03 12:00"},
+		{"older than a week", 10 * 24 * time.Hour, true, week, "2024-02-29 12:00"},
+		{"no limit", 10 * 24 * time.Hour, true, 0, "10 дней назад"},
+		{"relative dates off", 2 * time.Hour, false, week, "2024-03-10 10:00"},
+	}
+	for _, tt := range tests {
+		if got := historyDate(now.Add(-tt.ago), now, tt.relative, tt.limit); got != tt.want {
+			t.Errorf("%s: historyDate = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"os"
+	"strings"
+)
+
+// plainGlyphs swaps the glyphs the UI draws itself for ASCII markers
+var plainGlyphs = strings.NewReplacer(
+	"⚠️", "!",
+	"⚠", "!",
+	"⚡", "!",
+	"✓", "+",
+	"✗", "x",
+	"•", "*",
+	"◇", "o",
+	"⎇", "@",
+	"💡", ">",
+	"📌", "*",
+	"📝", "#",
+)
+
+// Plain returns s with the UI glyphs replaced by ASCII markers and any
+// other emoji dropped, for terminals that can't draw them
+func Plain(s string) string {
+	s = plainGlyphs.Replace(s)
+	return strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isEmoji reports whether r is drawn as an emoji on most terminals
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0x2600 && r <= 0x27BF:
+		return true
+	case r == 0xFE0F || r == 0x200D:
+		// Variation selector and joiner glue emoji sequences together
+		return true
+	}
+	return false
+}
+
+// SupportsEmoji guesses from the environment whether the terminal can draw
+// emoji: the Linux console and dumb terminals can't, nor can anything
+// running without a UTF-8 locale
+func SupportsEmoji() bool {
+	switch os.Getenv("TERM") {
+	case "linux", "dumb", "vt100", "vt220":
+		return false
+	}
+
+	// The first of these that is set decides the character set
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return true
+}
@@ -2,7 +2,9 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
@@ -35,6 +37,27 @@ var (
 	warningStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#F1FA8C")).
 			Bold(true)
+
+	mutedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272A4"))
+
+	diffAddStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#50FA7B"))
+
+	diffDeleteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F87"))
+
+	diffHunkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#8BE9FD"))
+
+	diffHeaderStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FAFAFA"))
+
+	matchStyle = lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#F1FA8C"))
 )
 
 // Renderer handles UI rendering
@@ -47,6 +70,14 @@ func NewRenderer() *Renderer {
 
 // View renders the complete UI
 func (r *Renderer) View(m models.Model) string {
+	if m.PlainText {
+		return fit(Plain(r.view(m)), m.Width, m.Height)
+	}
+	return fit(r.view(m), m.Width, m.Height)
+}
+
+// view renders the complete UI with all glyphs
+func (r *Renderer) view(m models.Model) string {
 	if m.Quitting {
 		return ""
 	}
@@ -54,41 +85,154 @@ func (r *Renderer) View(m models.Model) string {
 	var b strings.Builder
 
 	// Title
-	b.WriteString(titleStyle.Render(models.TitleMain))
+	b.WriteString(titleStyle.Render(title(m.Status)))
 	b.WriteString("\n\n")
 
 	// Show loading state
 	if m.Loading {
-		b.WriteString(normalStyle.Render(models.TextLoading + m.LoadingText))
+		b.WriteString(normalStyle.Render(models.T(models.TextLoading) + m.LoadingText))
 		b.WriteString("\n\n")
+		// Steps already done by a multi-step operation, like the save of
+		// save and sync
+		for _, entry := range m.RecentActivity {
+			b.WriteString(renderActivityEntry("  ", entry, false))
+			b.WriteString("\n")
+		}
 		return b.String()
 	}
 
 	// Show error if any
 	if m.Err != nil {
-		b.WriteString(errorStyle.Render(" ⚠️ Ошибка: " + m.Err.Error()))
+		b.WriteString(errorStyle.Render(models.T(" ⚠️ Ошибка: ") + m.Err.Error()))
 		b.WriteString("\n\n")
 	}
 
+	// The directory is gone, there is nothing else to show
+	if m.WorkDirGone {
+		b.WriteString(normalStyle.Render(models.T(models.TextWorkDirGone)))
+		return b.String()
+	}
+
 	// Show sync message if needed
 	if m.ShowSyncMessage {
 		if m.SyncMessage != "" {
 			b.WriteString(warningStyle.Render(" ⚠ " + m.SyncMessage))
 		} else {
-			b.WriteString(successStyle.Render(" ✓ Копия создана"))
+			b.WriteString(successStyle.Render(models.T(" ✓ Копия создана")))
 		}
 		b.WriteString("\n\n")
 	}
 
+	// Show result of the last operation
+	if m.Notice != "" {
+		b.WriteString(successStyle.Render(" ✓ " + m.Notice))
+		b.WriteString("\n\n")
+	}
+	if m.Warning != "" {
+		b.WriteString(warningStyle.Render(" ⚠ " + m.Warning))
+		b.WriteString("\n\n")
+	}
+
+	if m.Summary != nil {
+		b.WriteString(r.renderSummary(m.Summary))
+		b.WriteString("\n\n")
+	}
+
+	if m.SyncResult != nil {
+		b.WriteString(r.renderSyncResult(m.SyncResult))
+		b.WriteString("\n\n")
+	}
+
 	// Show description input mode
-	if m.DescriptionMode {
+	if m.RollbackPreview != nil {
+		b.WriteString(r.renderRollbackPreview(m))
+	} else if m.ConfirmFiles > 0 {
+		b.WriteString(warningStyle.Render(" ⚠ " + fmt.Sprintf(models.T(models.TextConfirmCheckpoint), m.ConfirmFiles, m.ConfirmLimit)))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render(models.T(models.HelpConfirmCheckpoint)))
+	} else if m.ConflictMode {
+		b.WriteString(r.renderConflict(m))
+	} else if m.ProfileMode {
+		b.WriteString(r.renderProfiles(m))
+	} else if m.ActivityMode {
+		b.WriteString(r.renderActivity(m))
+	} else if m.StashMode {
+		b.WriteString(r.renderStashes(m))
+	} else if m.BranchMode {
+		b.WriteString(r.renderBranches(m))
+	} else if m.RefTarget != nil {
+		b.WriteString(r.renderRefActions(m))
+	} else if m.RefMode {
+		b.WriteString(r.renderRefInput(m))
+	} else if m.SearchMode {
+		b.WriteString(r.renderSearch(m))
+	} else if m.BlameMode {
+		b.WriteString(r.renderBlameInput(m))
+	} else if m.NoteMode {
+		b.WriteString(r.renderNoteInput(m))
+	} else if m.BranchFromMode {
+		b.WriteString(r.renderBranchFromInput(m))
+	} else if m.TagMode {
+		b.WriteString(r.renderTagInput(m))
+	} else if m.RecentMode {
+		b.WriteString(r.renderRecent(m))
+	} else if m.IdentityMode {
+		b.WriteString(r.renderIdentityInput(m))
+	} else if m.DiffMode {
+		b.WriteString(r.renderDiff(m))
+	} else if m.DescriptionMode {
 		b.WriteString(r.renderDescriptionInput(m))
 	} else if m.HistoryMode {
 		b.WriteString(r.renderHistory(m))
+	} else if m.StagingMode {
+		b.WriteString(r.renderStaging(m))
+	} else if m.FilesMode {
+		b.WriteString(r.renderFiles(m))
 	} else {
-		// Show git status
-		if m.Status != nil {
-			b.WriteString(r.renderGitStatus(m.Status))
+		if m.Profile != "" {
+			b.WriteString(mutedStyle.Render(models.T(models.LabelProfile) + " " + m.Profile))
+			b.WriteString("\n")
+		}
+
+		if m.Status != nil && m.Status.Present != "" {
+			b.WriteString(warningStyle.Render(" ⚠ " + fmt.Sprintf(models.T(models.TextInThePast), m.Status.Present)))
+			b.WriteString("\n\n")
+		}
+
+		if m.Status != nil && m.Status.MergeInProgress {
+			b.WriteString(warningStyle.Render(" ⚠ " + models.T(models.TextMergeInProgress)))
+			b.WriteString("\n\n")
+		}
+
+		if m.ShowIdentityBanner() {
+			b.WriteString(warningStyle.Render(" ⚠ " + models.T(models.TextIdentityMissing)))
+			b.WriteString("\n")
+			b.WriteString(normalStyle.Render("   " + models.T(models.HelpIdentityBanner)))
+			b.WriteString("\n\n")
+		}
+
+		// Show git status, only under the launch directory unless the
+		// whole repository is asked for
+		status := m.Status
+		if status != nil && status.Scope != "" {
+			scope := fmt.Sprintf(models.T(models.LabelScope), status.Scope)
+			if m.WholeRepo {
+				scope += models.T(models.TextScopeWhole)
+			} else {
+				var hidden int
+				status, hidden = status.Scoped()
+				if hidden > 0 {
+					scope += fmt.Sprintf(models.T(models.TextScopeHidden), hidden)
+				}
+			}
+			b.WriteString(mutedStyle.Render(scope))
+			b.WriteString("\n")
+		}
+		if status != nil && m.UseCompactStatus() {
+			b.WriteString(r.renderGitStatusCompact(status))
+			b.WriteString("\n\n")
+		} else if status != nil {
+			b.WriteString(r.renderGitStatus(status))
 			b.WriteString("\n\n")
 		}
 
@@ -103,30 +247,62 @@ func (r *Renderer) View(m models.Model) string {
 func (r *Renderer) renderDescriptionInput(m models.Model) string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render(models.TitleDescription))
+	if m.SquashMode {
+		b.WriteString(titleStyle.Render(fmt.Sprintf(models.T(models.TitleSquash), m.SquashCount)))
+	} else if m.MarkerMode {
+		b.WriteString(titleStyle.Render(models.T(models.TitleMarker)))
+	} else {
+		b.WriteString(titleStyle.Render(models.T(models.TitleDescription)))
+	}
 	b.WriteString("\n\n")
 
-	b.WriteString(normalStyle.Render(models.PromptDescription))
+	b.WriteString(normalStyle.Render(models.T(models.PromptDescription)))
 	b.WriteString("\n")
 	b.WriteString(normalStyle.Render("> " + m.DescriptionInput + "_"))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if m.EstimatedSize > 0 {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(models.T(models.TextEstimatedSize), formatSize(m.EstimatedSize))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
-	b.WriteString(normalStyle.Render(models.PromptSuggestions))
+	b.WriteString(normalStyle.Render(models.T(models.PromptSuggestions)))
 	b.WriteString("\n")
 
-	for i, suggestion := range m.Suggestions {
-		if i < 10 { // Show only first 10 suggestions
-			prefix := "   "
-			if i < 9 {
-				prefix = fmt.Sprintf(" [%d] ", i+1)
-			}
-			b.WriteString(normalStyle.Render(prefix + suggestion))
-			b.WriteString("\n")
-		}
+	// Number exactly the suggestions the 1-9 keys pick
+	quickPicks := m.Suggestions[:min(len(m.Suggestions), models.QuickPickCount)]
+	for i, suggestion := range quickPicks {
+		b.WriteString(normalStyle.Render(fmt.Sprintf(" [%d] %s", i+1, suggestion)))
+		b.WriteString("\n")
+	}
+	if extra := m.Suggestions[len(quickPicks):]; len(extra) > 0 {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(models.T(models.TextMoreSuggestions), strings.Join(extra, " · "))))
+		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(normalStyle.Render(models.HelpDescription))
+	b.WriteString(normalStyle.Render(models.T(models.HelpDescription)))
+
+	return b.String()
+}
+
+// renderGitStatusCompact squeezes the status into one line for short
+// terminals: branch, ahead/behind, file counts and the clean/dirty glyph
+func (r *Renderer) renderGitStatusCompact(status *models.GitStatus) string {
+	var b strings.Builder
+
+	branchText := "⎇ " + status.Branch
+	if status.Ahead > 0 || status.Behind > 0 {
+		branchText += fmt.Sprintf(" ↑%d ↓%d", status.Ahead, status.Behind)
+	}
+	b.WriteString(branchStyle(status).Render(branchText))
+	b.WriteString(normalStyle.Render(fmt.Sprintf("  ✓%d •%d ?%d ✗%d  ",
+		len(status.Staged), len(status.Modified), len(status.Untracked), len(status.Deleted))))
+	if status.IsClean {
+		b.WriteString(successStyle.Render("✓"))
+	} else {
+		b.WriteString(warningStyle.Render("⚡"))
+	}
 
 	return b.String()
 }
@@ -136,127 +312,1125 @@ func (r *Renderer) renderGitStatus(status *models.GitStatus) string {
 	var b strings.Builder
 
 	// Branch info
-	branchText := fmt.Sprintf("%s %s", models.LabelBranch, status.Branch)
+	branchText := fmt.Sprintf("%s %s", models.T(models.LabelBranch), status.Branch)
 	if status.Ahead > 0 || status.Behind > 0 {
 		branchText += fmt.Sprintf(" (↑%d ↓%d)", status.Ahead, status.Behind)
 	}
-	b.WriteString(normalStyle.Render(branchText))
+	b.WriteString(branchStyle(status).Render(branchText))
 	b.WriteString("\n")
 
-	// Last commit
-	if status.LastCommit != "" {
-		b.WriteString(normalStyle.Render(models.LabelLastCommit + " " + status.LastCommit))
+	// A gentle nudge to sync, counted in checkpoints rather than commits
+	if status.Unpushed > 0 {
+		b.WriteString(warningStyle.Render(fmt.Sprintf(models.T(models.TextUnpushedCheckpoints), status.Unpushed,
+			plural(status.Unpushed, "несохранённый", "несохранённых", "несохранённых"),
+			plural(status.Unpushed, "момент", "момента", "моментов"))))
 		b.WriteString("\n")
 	}
 
+	// Last commit
+	lastCommit := models.T(models.TextNoCommits)
+	if status.LastCommitHash != "" {
+		lastCommit = fmt.Sprintf("%s %.7s — %s, %s",
+			firstLine(status.LastCommitMessage),
+			status.LastCommitHash,
+			status.LastCommitAuthor,
+			relativeTime(status.LastCommitDate, time.Now()),
+		)
+	}
+	b.WriteString(normalStyle.Render(models.T(models.LabelLastCommit) + " " + lastCommit))
+	b.WriteString("\n")
+
 	// Status
 	if status.IsClean {
-		b.WriteString(successStyle.Render(models.TextClean))
+		b.WriteString(successStyle.Render(models.T(models.TextClean)))
 	} else {
-		b.WriteString(warningStyle.Render(models.TextDirty))
+		b.WriteString(warningStyle.Render(models.T(models.TextDirty)))
 	}
 	b.WriteString("\n\n")
 
-	// File changes
-	if len(status.Staged) > 0 {
-		b.WriteString(successStyle.Render(models.LabelStaged))
+	// File changes, renames get a section of their own below
+	staged := withoutRenames(status, status.Staged)
+	untracked := withoutRenames(status, status.Untracked)
+	deleted := withoutRenames(status, status.Deleted)
+	if len(staged) > 0 {
+		b.WriteString(successStyle.Render(models.T(models.LabelStaged)))
 		b.WriteString("\n")
-		for _, file := range status.Staged {
-			b.WriteString(normalStyle.Render("  ✓ " + file))
+		for _, file := range staged {
+			b.WriteString(fileStyle(status, file).Render("  ✓ " + file))
 			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	}
 
 	if len(status.Modified) > 0 {
-		b.WriteString(warningStyle.Render(models.LabelModified))
+		b.WriteString(warningStyle.Render(models.T(models.LabelModified)))
 		b.WriteString("\n")
 		for _, file := range status.Modified {
-			b.WriteString(normalStyle.Render("  • " + file))
+			line := "  • " + file
+			if status.IsLarge(file) {
+				line += models.T(models.TextLargeFile)
+			}
+			b.WriteString(fileStyle(status, file).Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(untracked) > 0 {
+		b.WriteString(normalStyle.Render(models.T(models.LabelUntracked)))
+		b.WriteString("\n")
+		for _, file := range untracked {
+			b.WriteString(fileStyle(status, file).Render("  ? " + file))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(deleted) > 0 {
+		b.WriteString(errorStyle.Render(models.T(models.LabelDeleted)))
+		b.WriteString("\n")
+		for _, file := range deleted {
+			b.WriteString(fileStyle(status, file).Render("  ✗ " + file))
 			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	}
 
-	if len(status.Untracked) > 0 {
-		b.WriteString(normalStyle.Render(models.LabelUntracked))
+	if len(status.Renamed) > 0 {
+		b.WriteString(normalStyle.Render(models.T(models.LabelRenamed)))
 		b.WriteString("\n")
-		for _, file := range status.Untracked {
-			b.WriteString(normalStyle.Render("  ? " + file))
+		for _, rename := range status.Renamed {
+			b.WriteString(fileStyle(status, rename.To).Render("  → " + rename.From + " → " + rename.To))
 			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	}
 
+	if len(status.Submodules) > 0 {
+		b.WriteString(normalStyle.Render(models.T(models.LabelSubmodules)))
+		b.WriteString("\n")
+		for _, sub := range status.Submodules {
+			line := "  ◇ " + sub.Path
+			if !sub.Initialized {
+				line += models.T(models.TextSubmoduleNotInit)
+			} else if !sub.Clean {
+				line += models.T(models.TextSubmoduleChanged)
+			}
+			b.WriteString(normalStyle.Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString(warningStyle.Render(models.T(models.TextSubmodulesWarning)))
+		b.WriteString("\n\n")
+	}
+
 	return b.String()
 }
 
+// withoutRenames drops the files that are listed as renames
+func withoutRenames(status *models.GitStatus, files []string) []string {
+	var kept []string
+	for _, file := range files {
+		if !status.IsRenamed(file) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// fileStyle mutes files configured as noise
+func fileStyle(status *models.GitStatus, file string) lipgloss.Style {
+	if status.IsNoise(file) {
+		return mutedStyle
+	}
+	return normalStyle
+}
+
+// branchStyle picks the branch line color from the sync state:
+// green when in sync, yellow with unpushed work, red when behind or diverged
+func branchStyle(status *models.GitStatus) lipgloss.Style {
+	switch {
+	case status.Behind > 0:
+		return errorStyle
+	case status.Ahead > 0:
+		return warningStyle
+	default:
+		return successStyle
+	}
+}
+
+// firstLine returns the subject line of a commit message
+func firstLine(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return subject
+}
+
 // renderMenu displays the action menu
 func (r *Renderer) renderMenu(m models.Model) string {
 	menuItems := m.GetMenuItems()
 	var b strings.Builder
 
-	b.WriteString(normalStyle.Render(models.LabelActions))
+	b.WriteString(normalStyle.Render(models.T(models.LabelActions)))
 	b.WriteString("\n")
 
 	for i, item := range menuItems {
 		if i == m.Selected {
-			b.WriteString(selectedStyle.Render("▶ " + item))
+			b.WriteString(selectedStyle.Render("▶ " + models.T(item)))
 		} else {
-			b.WriteString(normalStyle.Render("  " + item))
+			b.WriteString(normalStyle.Render("  " + models.T(item)))
 		}
 		b.WriteString("\n")
+		if i == m.Selected && !m.HideMenuHelp && models.MenuDescriptions[item] != "" {
+			b.WriteString(mutedStyle.Render("    " + models.T(models.MenuDescriptions[item])))
+			b.WriteString("\n")
+		}
 	}
 
 	b.WriteString("\n")
-	b.WriteString(normalStyle.Render(models.HelpMain))
+	b.WriteString(normalStyle.Render(models.T(models.HelpMain)))
 	b.WriteString("\n")
-	b.WriteString(normalStyle.Render(models.HelpHotkeys))
+	b.WriteString(normalStyle.Render(models.T(models.HelpHotkeys)))
 
 	return b.String()
 }
 
-// renderHistory displays the checkpoint history
-func (r *Renderer) renderHistory(m models.Model) string {
+// renderSummary displays the recap of a destructive operation
+func (r *Renderer) renderSummary(summary *models.OperationSummary) string {
+	var b strings.Builder
+
+	b.WriteString(warningStyle.Render(summary.Title))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.TextSummaryHeads), summary.OldHead, summary.NewHead)))
+	b.WriteString("\n")
+
+	if summary.Affected > 0 {
+		b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.TextSummaryAffected), summary.Affected)))
+		b.WriteString("\n")
+		for _, commit := range summary.Commits {
+			b.WriteString(mutedStyle.Render("  − " + commit))
+			b.WriteString("\n")
+		}
+		if more := summary.Affected - len(summary.Commits); more > 0 {
+			b.WriteString(mutedStyle.Render("  " + fmt.Sprintf(models.T(models.TextSummaryMore), more)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(mutedStyle.Render(models.T(models.HelpSummary)))
+
+	return b.String()
+}
+
+// renderRollbackPreview displays what a rollback would change and asks to
+// confirm it
+func (r *Renderer) renderRollbackPreview(m models.Model) string {
 	var b strings.Builder
+	preview := m.RollbackPreview
 
-	b.WriteString(normalStyle.Render(models.LabelHistory))
+	b.WriteString(warningStyle.Render(fmt.Sprintf(models.T(models.TitlePreview), preview.Hash, preview.Message)))
 	b.WriteString("\n\n")
 
-	if len(m.Checkpoints) == 0 {
-		b.WriteString(normalStyle.Render(models.TextNoCheckpoints))
-		b.WriteString("\n\n")
+	if len(preview.Files) == 0 {
+		b.WriteString(normalStyle.Render(models.T(models.TextPreviewNothing)))
+		b.WriteString("\n")
 	} else {
-		for i, checkpoint := range m.Checkpoints {
-			prefix := "  "
-			if i == m.HistorySelected {
-				prefix = "▶ "
-			}
+		counts := map[models.PreviewChange]int{}
+		for _, file := range preview.Files {
+			counts[file.Change]++
+		}
+		b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.TextPreviewCounts),
+			counts[models.PreviewModified], counts[models.PreviewCreated], counts[models.PreviewDeleted])))
+		b.WriteString("\n")
 
-			indicator := ""
-			if checkpoint.IsCurrent {
-				indicator = models.TextCurrent
+		pageSize := m.DiffPageSize()
+		for i, file := range preview.Files {
+			if i == pageSize {
+				b.WriteString(mutedStyle.Render("  " + fmt.Sprintf(models.T(models.TextSummaryMore), len(preview.Files)-i)))
+				b.WriteString("\n")
+				break
 			}
+			switch file.Change {
+			case models.PreviewCreated:
+				b.WriteString(successStyle.Render("  + " + file.Path))
+			case models.PreviewDeleted:
+				b.WriteString(errorStyle.Render("  − " + file.Path))
+			default:
+				b.WriteString(warningStyle.Render("  ~ " + file.Path))
+			}
+			b.WriteString("\n")
+		}
+	}
 
-			line := fmt.Sprintf("%s%s %.7s - %s%s",
-				prefix,
-				checkpoint.Date.Format("2006-01-02 15:04"),
-				checkpoint.Hash,
-				checkpoint.Message,
-				indicator,
-			)
+	if preview.Commits > 0 {
+		b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.TextPreviewCommits), preview.Commits)))
+		b.WriteString("\n")
+	}
+	if preview.Unpushed > 0 {
+		b.WriteString(errorStyle.Render(fmt.Sprintf(models.T(models.TextPreviewUnpushed), preview.Unpushed)))
+		b.WriteString("\n")
+	}
+	if preview.Unsaved > 0 && preview.AutoSaved {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(models.T(models.TextPreviewUnsavedSaved), preview.Unsaved)))
+		b.WriteString("\n")
+	} else if preview.Unsaved > 0 {
+		b.WriteString(errorStyle.Render(fmt.Sprintf(models.T(models.TextPreviewUnsavedLost), preview.Unsaved)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
-			if i == m.HistorySelected {
-				b.WriteString(selectedStyle.Render(line))
-			} else {
-				b.WriteString(normalStyle.Render(line))
-			}
+	help := models.T(models.HelpPreview)
+	if preview.Unsaved > 0 && !preview.AutoSaved {
+		help = models.T(models.HelpPreviewUnsaved)
+	}
+	b.WriteString(normalStyle.Render(help))
+
+	return b.String()
+}
+
+// renderSyncResult draws what a sync moved between the local branch and the
+// remote as arrows between the two
+func (r *Renderer) renderSyncResult(result *models.SyncMsg) string {
+	var b strings.Builder
+
+	b.WriteString(successStyle.Render(" ✓ " + models.T(models.LabelSyncResult)))
+	b.WriteString("\n")
+
+	local := "  " + models.T(models.LabelSyncLocal) + " ●"
+	remote := "● " + models.T(models.LabelSyncRemote)
+	if result.Received == 0 && result.Sent == 0 {
+		b.WriteString(normalStyle.Render(local + "═══════" + remote + "  " + models.T(models.TextSyncInSync)))
+		b.WriteString("\n")
+	}
+	if result.Received > 0 {
+		b.WriteString(normalStyle.Render(local + "◀── " + fmt.Sprintf(models.T(models.TextSyncReceived), result.Received) + " ──" + remote))
+		b.WriteString("\n")
+	}
+	if result.Sent > 0 {
+		line := local + "─── " + fmt.Sprintf(models.T(models.TextSyncSent), result.Sent) + " ─▶" + remote
+		if result.Forced {
+			b.WriteString(warningStyle.Render(line + models.T(models.TextSyncForced)))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	if result.Conflict {
+		b.WriteString(warningStyle.Render("  " + models.T(models.TextSyncResolved)))
+		b.WriteString("\n")
+	}
+	// What others had pushed is gone from the remote, say what and where
+	// to find it
+	if summary := result.Summary; result.Forced && summary != nil && summary.Affected > 0 {
+		b.WriteString(errorStyle.Render("  " + fmt.Sprintf(models.T(models.TextSyncOverwritten), summary.Affected,
+			plural(summary.Affected, "чужой", "чужих", "чужих"),
+			plural(summary.Affected, "момент", "момента", "моментов"), summary.OldHead)))
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderConflict displays the choice of how to resolve a rejected pull
+func (r *Renderer) renderConflict(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(warningStyle.Render(models.T(models.LabelConflict)))
+	b.WriteString("\n")
+	if m.ConflictReason != "" {
+		b.WriteString(mutedStyle.Render(m.ConflictReason))
+		b.WriteString("\n")
+	}
+	// Keeping the local version force pushes, say whose work that discards
+	if !m.ConflictCanForce {
+		b.WriteString(mutedStyle.Render(models.T(models.TextConflictNoForce)))
+		b.WriteString("\n")
+	} else if summary := m.ConflictOverwrites; summary != nil && summary.Affected > 0 {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render(fmt.Sprintf(models.T(models.TextConflictOverwrites), summary.Affected)))
+		b.WriteString("\n")
+		for _, commit := range summary.Commits {
+			b.WriteString(mutedStyle.Render("  − " + commit))
+			b.WriteString("\n")
+		}
+		if more := summary.Affected - len(summary.Commits); more > 0 {
+			b.WriteString(mutedStyle.Render("  " + fmt.Sprintf(models.T(models.TextSummaryMore), more)))
 			b.WriteString("\n")
 		}
+	}
+	b.WriteString("\n")
+
+	for i, option := range m.ConflictOptions() {
+		if i == m.ConflictSelected {
+			b.WriteString(selectedStyle.Render("▶ " + models.T(models.ConflictLabel(option))))
+		} else {
+			b.WriteString(normalStyle.Render("  " + models.T(models.ConflictLabel(option))))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpConflict)))
+
+	return b.String()
+}
+
+// renderProfiles displays the config profiles to switch between
+func (r *Renderer) renderProfiles(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelProfiles)))
+	b.WriteString("\n\n")
+
+	for i, name := range m.Profiles {
+		label := name
+		if name == "" {
+			label = models.T(models.TextNoProfile)
+		}
+		if name == m.Profile {
+			label += " ✓"
+		}
+		if i == m.ProfileSelected {
+			b.WriteString(selectedStyle.Render("▶ " + label))
+		} else {
+			b.WriteString(normalStyle.Render("  " + label))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpProfiles)))
+
+	return b.String()
+}
+
+// renderActivity displays the journal of operations, a page around the
+// selected entry
+func (r *Renderer) renderActivity(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelActivity)))
+	b.WriteString("\n\n")
+
+	if len(m.Activity) == 0 {
+		b.WriteString(normalStyle.Render(models.T(models.TextNoActivity)))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render(models.T(models.HelpActivity)))
+		return b.String()
+	}
+
+	pageSize := m.DiffPageSize()
+	start := max(m.ActivitySelected-pageSize+1, 0)
+	end := min(start+pageSize, len(m.Activity))
+
+	for i, entry := range m.Activity[start:end] {
+		prefix := "  "
+		if start+i == m.ActivitySelected {
+			prefix = "▶ "
+		}
+		b.WriteString(renderActivityEntry(prefix, entry, true))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(normalStyle.Render(models.T(models.HelpActivity)))
+
+	return b.String()
+}
+
+// renderActivityEntry draws one line of the journal, withRepo adds the
+// date and the project it happened in
+func renderActivityEntry(prefix string, entry models.ActivityEntry, withRepo bool) string {
+	mark := "✓"
+	style := successStyle
+	if !entry.Success {
+		mark = "✗"
+		style = errorStyle
+	}
+
+	line := normalStyle.Render(prefix)
+	if withRepo {
+		line = normalStyle.Render(prefix + entry.Time.Format("2006-01-02 15:04") + " ")
+	}
+	line += style.Render(mark + " " + models.T(entry.Action))
+	if withRepo {
+		line += mutedStyle.Render(" " + filepath.Base(entry.Repo))
+	}
+	return line + normalStyle.Render(" — "+entry.Outcome)
+}
+
+// renderStashes displays the stash list, a page around the selected entry
+func (r *Renderer) renderStashes(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelStashes)))
+	b.WriteString("\n\n")
+
+	if len(m.Stashes) == 0 {
+		b.WriteString(normalStyle.Render(models.T(models.TextNoStashes)))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render(models.T(models.HelpStashes)))
+		return b.String()
+	}
+
+	pageSize := m.DiffPageSize()
+	start := max(m.StashSelected-pageSize+1, 0)
+	end := min(start+pageSize, len(m.Stashes))
+	now := time.Now()
+
+	for i, entry := range m.Stashes[start:end] {
+		line := fmt.Sprintf("stash@{%d} %s", entry.Index, entry.Message)
+		if start+i == m.StashSelected {
+			b.WriteString(selectedStyle.Render("▶ " + line))
+		} else {
+			b.WriteString(normalStyle.Render("  " + line))
+		}
+		b.WriteString(mutedStyle.Render(" " + relativeTime(entry.Date, now)))
 		b.WriteString("\n")
 	}
+	b.WriteString("\n")
 
-	b.WriteString(normalStyle.Render(models.HelpHistory))
+	b.WriteString(normalStyle.Render(models.T(models.HelpStashes)))
 
 	return b.String()
 }
+
+// renderBranches displays the local branches, or the prompt for the name of
+// a new one
+func (r *Renderer) renderBranches(m models.Model) string {
+	var b strings.Builder
+
+	if m.BranchCreating {
+		b.WriteString(normalStyle.Render(models.T(models.PromptBranch)))
+		b.WriteString("\n")
+		b.WriteString(normalStyle.Render("> " + m.BranchInput + "_"))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render(models.T(models.HelpBranchInput)))
+		return b.String()
+	}
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelBranches)))
+	b.WriteString("\n\n")
+
+	pageSize := m.DiffPageSize()
+	start := max(m.BranchSelected-pageSize+1, 0)
+	end := min(start+pageSize, len(m.Branches))
+	now := time.Now()
+
+	for i, branch := range m.Branches[start:end] {
+		label := branch.Name
+		if branch.Current {
+			label += " ✓"
+		}
+		if start+i == m.BranchSelected {
+			b.WriteString(selectedStyle.Render("▶ " + label))
+		} else {
+			b.WriteString(normalStyle.Render("  " + label))
+		}
+		if !branch.Date.IsZero() {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf(" %.7s %s", branch.Hash, relativeTime(branch.Date, now))))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(normalStyle.Render(models.T(models.HelpBranches)))
+
+	return b.String()
+}
+
+// renderRefInput displays the prompt for a ref to jump to
+func (r *Renderer) renderRefInput(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.PromptRef)))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.RefInput + "_"))
+	b.WriteString("\n\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpRefInput)))
+
+	return b.String()
+}
+
+// renderNoteInput displays the editor for a checkpoint note
+func (r *Renderer) renderNoteInput(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.PromptNote), m.NoteHash)))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.NoteInput + "_"))
+	b.WriteString("\n\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpNote)))
+
+	return b.String()
+}
+
+// renderBranchFromInput displays the prompt for the name of a branch
+// starting at a checkpoint
+func (r *Renderer) renderBranchFromInput(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.PromptBranchFrom), m.BranchFromHash)))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.BranchFromInput + "_"))
+	b.WriteString("\n\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpBranchInput)))
+
+	return b.String()
+}
+
+// renderTagInput displays the prompt for the name of a checkpoint tag
+func (r *Renderer) renderTagInput(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.PromptTag), m.TagHash)))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.TagInput + "_"))
+	b.WriteString("\n\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpTagInput)))
+
+	return b.String()
+}
+
+// renderBlameInput displays the prompt for the file to blame
+func (r *Renderer) renderBlameInput(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.PromptBlame), m.BlameHash)))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.BlameInput + "_"))
+	b.WriteString("\n\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpBlame)))
+
+	return b.String()
+}
+
+// renderRecent displays the recently opened repositories
+func (r *Renderer) renderRecent(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelRecent)))
+	b.WriteString("\n\n")
+
+	if len(m.Recent) == 0 {
+		b.WriteString(normalStyle.Render(models.T(models.TextNoRecent)))
+		b.WriteString("\n\n")
+	} else {
+		for i, path := range m.Recent {
+			line := filepath.Base(path) + " "
+			if i == m.RecentSelected {
+				b.WriteString(selectedStyle.Render("▶ " + line))
+			} else {
+				b.WriteString(normalStyle.Render("  " + line))
+			}
+			b.WriteString(mutedStyle.Render(path))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(normalStyle.Render(models.T(models.HelpRecent)))
+
+	return b.String()
+}
+
+// renderIdentityInput displays the prompt for the git name or email
+func (r *Renderer) renderIdentityInput(m models.Model) string {
+	var b strings.Builder
+
+	prompt := models.T(models.PromptIdentityName)
+	if m.IdentityName != "" {
+		prompt = models.T(models.PromptIdentityEmail)
+	}
+	b.WriteString(normalStyle.Render(prompt))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.IdentityInput + "_"))
+	b.WriteString("\n\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpIdentityInput)))
+
+	return b.String()
+}
+
+// renderSearch displays the search prompt and a page of matching checkpoints
+func (r *Renderer) renderSearch(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.PromptSearch)))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render("> " + m.SearchInput + "_"))
+	b.WriteString("\n\n")
+
+	results := m.SearchResults()
+	if len(results) == 0 {
+		b.WriteString(mutedStyle.Render(models.T(models.TextNoMatches)))
+		b.WriteString("\n\n")
+	} else {
+		pageSize := m.DiffPageSize()
+		start := max(m.SearchSelected-pageSize+1, 0)
+		end := min(start+pageSize, len(results))
+		for i, checkpoint := range results[start:end] {
+			line := fmt.Sprintf("%s %.7s - %s",
+				checkpoint.Date.Format("2006-01-02 15:04"),
+				checkpoint.Hash,
+				firstLine(checkpoint.Message),
+			)
+			if start+i == m.SearchSelected {
+				b.WriteString(selectedStyle.Render("▶ " + line))
+			} else {
+				b.WriteString(normalStyle.Render("  " + line))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(normalStyle.Render(models.T(models.HelpSearch)))
+
+	return b.String()
+}
+
+// renderRefActions displays the commit found by a ref and what can be done with it
+func (r *Renderer) renderRefActions(m models.Model) string {
+	var b strings.Builder
+	checkpoint := m.RefTarget
+
+	tags := ""
+	if len(checkpoint.Tags) > 0 {
+		tags = " [" + strings.Join(checkpoint.Tags, ", ") + "]"
+	}
+	indicator := ""
+	if checkpoint.IsCurrent {
+		indicator = models.T(models.TextCurrent)
+	}
+
+	line := fmt.Sprintf("%.7s - %s%s%s", checkpoint.Hash, firstLine(checkpoint.Message), tags, indicator)
+	b.WriteString(successStyle.Render(line))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(checkpoint.Author + ", " + relativeTime(checkpoint.Date, time.Now())))
+	b.WriteString("\n\n")
+
+	for i, action := range models.RefActions {
+		if i == m.RefSelected {
+			b.WriteString(selectedStyle.Render("▶ " + models.T(action.Label)))
+		} else {
+			b.WriteString(normalStyle.Render("  " + models.T(action.Label)))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render(models.T(models.HelpRefActions)))
+
+	return b.String()
+}
+
+// renderHistory displays the checkpoint history
+func (r *Renderer) renderHistory(m models.Model) string {
+	var b strings.Builder
+
+	label := models.T(models.LabelHistory)
+	if m.HistoryBranch != "" {
+		label = fmt.Sprintf(models.T(models.LabelBranchHistory), m.HistoryBranch)
+	}
+	b.WriteString(normalStyle.Render(label))
+	b.WriteString("\n\n")
+
+	if m.HistoryShallow {
+		b.WriteString(warningStyle.Render(models.T(models.TextShallowHistory)))
+		b.WriteString("\n\n")
+	}
+
+	if m.HistoryFiltering || m.HistoryFilter != "" {
+		filter := "/ " + m.HistoryFilter
+		if m.HistoryFiltering {
+			filter += "_"
+		}
+		b.WriteString(normalStyle.Render(filter))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.Checkpoints) == 0 {
+		b.WriteString(normalStyle.Render(models.T(models.TextNoCheckpoints)))
+		b.WriteString("\n\n")
+	} else {
+		b.WriteString(mutedStyle.Render(historySummary(m)))
+		b.WriteString("\n\n")
+
+		// Bars are scaled against the biggest checkpoint counted so far
+		largest := 0
+		for _, stat := range m.HistoryStats {
+			largest = max(largest, stat.Added+stat.Deleted)
+		}
+
+		now := time.Now()
+		formatDate := func(date time.Time) string {
+			return historyDate(date, now, m.HistoryRelativeDates, m.RelativeDatesLimit)
+		}
+
+		rows := m.HistoryRows()
+		if len(rows) == 0 {
+			b.WriteString(normalStyle.Render(models.T(models.TextNoMatches)))
+			b.WriteString("\n")
+		}
+		start, end := m.HistoryWindow(rows)
+		if start > 0 {
+			b.WriteString(mutedStyle.Render("  " + fmt.Sprintf(models.T(models.TextHistoryMoreAbove), start)))
+			b.WriteString("\n")
+		}
+		for _, row := range rows[start:end] {
+			i, checkpoint := row.Index, m.Checkpoints[row.Index]
+			selected := m.HistorySelected >= i && m.HistorySelected < i+row.Count
+			prefix := "  "
+			if selected {
+				prefix = "▶ "
+			}
+			style := normalStyle
+			if selected {
+				style = selectedStyle
+			}
+
+			if row.Count > 1 {
+				// Newest first, so the span runs from the last one in the group
+				group := m.Checkpoints[i : i+row.Count]
+				line := prefix + fmt.Sprintf(models.T(models.TextCheckpointGroup), row.Count,
+					plural(row.Count, "сейв", "сейва", "сейвов"),
+					formatDate(group[len(group)-1].Date), formatDate(checkpoint.Date))
+				for _, member := range group {
+					if member.IsCurrent {
+						line += models.T(models.TextCurrent)
+					}
+				}
+				b.WriteString(style.Render(line))
+				b.WriteString("\n")
+				continue
+			}
+
+			indicator := ""
+			if checkpoint.Pinned {
+				indicator += models.T(models.TextPinnedMarker)
+			}
+			if checkpoint.Note != "" {
+				indicator += models.T(models.TextNoteMarker)
+			}
+			if checkpoint.IsCurrent {
+				indicator += models.T(models.TextCurrent)
+			}
+			if checkpoint.Hash == m.HistoryCompareBase {
+				indicator += models.T(models.TextCompareBaseMarker)
+			}
+
+			tags := ""
+			if len(checkpoint.Tags) > 0 {
+				tags = " [" + strings.Join(checkpoint.Tags, ", ") + "]"
+			}
+
+			b.WriteString(style.Render(prefix + formatDate(checkpoint.Date) + " "))
+			b.WriteString(highlightMatches(fmt.Sprintf("%.7s", checkpoint.Hash), m.HistoryFilter, style))
+			if len(m.HistoryStats) > 0 {
+				b.WriteString(" ")
+				b.WriteString(renderStatBar(m.HistoryStats[checkpoint.Hash], largest))
+			}
+			b.WriteString(style.Render(" - "))
+			b.WriteString(highlightMatches(firstLine(checkpoint.Message), m.HistoryFilter, style))
+			b.WriteString(style.Render(tags + indicator))
+			b.WriteString("\n")
+		}
+		if end < len(rows) {
+			b.WriteString(mutedStyle.Render("  " + fmt.Sprintf(models.T(models.TextHistoryMoreBelow), len(rows)-end)))
+			b.WriteString("\n")
+		} else if m.HasMore {
+			// The end of the loaded list isn't the end of the history
+			older := models.TextHistoryOlder
+			if m.HistoryLoadingMore {
+				older = models.TextHistoryLoadingOlder
+			}
+			b.WriteString(mutedStyle.Render("  " + models.T(older)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+		if m.HistoryCompareBase != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf(models.T(models.TextCompareHint), m.HistoryCompareBase)))
+			b.WriteString("\n\n")
+		}
+
+		if m.HistoryDetail && m.HistorySelected < len(m.Checkpoints) {
+			b.WriteString(r.renderCheckpointDetail(m.Checkpoints[m.HistorySelected], m.Width))
+			b.WriteString("\n\n")
+		}
+	}
+
+	b.WriteString(normalStyle.Render(models.T(models.HelpHistory)))
+
+	return b.String()
+}
+
+// highlightMatches renders text in style with every word of query found in
+// it, ignoring case, picked out
+func highlightMatches(text, query string, style lipgloss.Style) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if query == "" || len(lower) != len(runes) {
+		return style.Render(text)
+	}
+
+	matched := make([]bool, len(runes))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		needle := []rune(word)
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == word {
+				for j := i; j < i+len(needle); j++ {
+					matched[j] = true
+				}
+			}
+		}
+	}
+
+	// Render runs of matched and unmatched runes
+	var b strings.Builder
+	for start := 0; start < len(runes); {
+		end := start
+		for end < len(runes) && matched[end] == matched[start] {
+			end++
+		}
+		if matched[start] {
+			b.WriteString(matchStyle.Render(string(runes[start:end])))
+		} else {
+			b.WriteString(style.Render(string(runes[start:end])))
+		}
+		start = end
+	}
+	return b.String()
+}
+
+// statBarWidth is how many characters the diff-stat bar of a checkpoint takes
+const statBarWidth = 10
+
+// renderStatBar draws the lines a checkpoint added and deleted as a bar of
+// green pluses and red minuses, as long relative to largest as the change
+// is. Checkpoints not counted yet get a blank bar.
+func renderStatBar(stat models.DiffStat, largest int) string {
+	total := stat.Added + stat.Deleted
+	if total == 0 || largest == 0 {
+		return strings.Repeat(" ", statBarWidth)
+	}
+
+	// Every change gets at least one character
+	length := max(total*statBarWidth/largest, 1)
+	added := stat.Added * length / total
+	if added == 0 && stat.Added > 0 {
+		added = 1
+	}
+	deleted := length - added
+
+	return diffAddStyle.Render(strings.Repeat("+", added)) +
+		diffDeleteStyle.Render(strings.Repeat("-", deleted)) +
+		strings.Repeat(" ", statBarWidth-length)
+}
+
+// title names the repository and branch in the header, or is the static
+// title until the status is loaded
+func title(status *models.GitStatus) string {
+	if status == nil || status.Root == "" {
+		return models.T(models.TitleMain)
+	}
+	return fmt.Sprintf(models.T(models.TitleRepo), filepath.Base(status.Root), status.Branch)
+}
+
+// historySummary sums up the loaded history in one line: how many
+// checkpoints, the dates they span and how many the remote lacks
+func historySummary(m models.Model) string {
+	// The list is newest first
+	newest := m.Checkpoints[0].Date
+	oldest := m.Checkpoints[len(m.Checkpoints)-1].Date
+
+	format := models.TextHistorySummary
+	if m.HasMore {
+		format = models.TextHistoryPartSummary
+	}
+	summary := fmt.Sprintf(models.T(format), len(m.Checkpoints),
+		oldest.Format("2006-01-02"), newest.Format("2006-01-02"))
+	if m.Status != nil && m.Status.Ahead > 0 {
+		summary += fmt.Sprintf(models.T(models.TextHistoryUnpushed), m.Status.Ahead)
+	}
+	return summary
+}
+
+// renderCheckpointDetail displays the complete message of a checkpoint, the
+// subject highlighted and the body wrapped to the terminal width
+func (r *Renderer) renderCheckpointDetail(checkpoint models.Checkpoint, width int) string {
+	var b strings.Builder
+
+	if width <= 0 {
+		width = 80
+	}
+	wrap := normalStyle.Width(max(width-2, 20))
+
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s · %s · %s",
+		checkpoint.Hash, checkpoint.Author, checkpoint.Date.Format("2006-01-02 15:04"))))
+	b.WriteString("\n")
+
+	subject, body, _ := strings.Cut(strings.TrimSpace(checkpoint.Message), "\n")
+	b.WriteString(selectedStyle.Width(max(width-2, 20)).Render(subject))
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString("\n\n")
+		b.WriteString(wrap.Render(body))
+	}
+
+	if checkpoint.Note != "" {
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render(models.T(models.LabelNote)))
+		b.WriteString("\n")
+		b.WriteString(wrap.Render(checkpoint.Note))
+	}
+
+	return b.String()
+}
+
+// renderFiles displays the navigable list of changed files
+func (r *Renderer) renderFiles(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelFiles)))
+	b.WriteString("\n\n")
+
+	files := m.Status.Files()
+	if len(files) == 0 {
+		b.WriteString(normalStyle.Render(models.T(models.TextNoFiles)))
+		b.WriteString("\n\n")
+	} else {
+		for i, file := range files {
+			prefix := "  "
+			if i == m.FilesSelected {
+				prefix = "▶ "
+			}
+
+			line := prefix + fileMarker(file.Category) + " " + file.Path
+			if i == m.FilesSelected {
+				b.WriteString(selectedStyle.Render(line))
+			} else {
+				b.WriteString(normalStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(normalStyle.Render(models.T(models.HelpFiles)))
+
+	return b.String()
+}
+
+// renderDiff displays the visible window of the current patch
+func (r *Renderer) renderDiff(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(m.DiffTitle))
+	b.WriteString("\n\n")
+
+	help := models.T(models.HelpDiff)
+	if m.DiffWorkingTree {
+		help = models.T(models.HelpWorkingTreeDiff)
+		if file := m.DiffFile(); file != "" {
+			line := fmt.Sprintf(models.T(models.LabelDiffFile), file)
+			if m.Status != nil && m.Status.IsStagedOnly(file) {
+				b.WriteString(successStyle.Render(line + models.T(models.TextDiffFileStaged)))
+			} else {
+				b.WriteString(normalStyle.Render(line))
+			}
+			b.WriteString("\n\n")
+		}
+	}
+
+	if m.DiffPatch == "" {
+		b.WriteString(normalStyle.Render(models.T(models.TextNoDiff)))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render(help))
+		return b.String()
+	}
+
+	lines := strings.Split(strings.TrimSuffix(m.DiffPatch, "\n"), "\n")
+	start := min(m.DiffOffset, len(lines))
+	end := min(start+m.DiffPageSize(), len(lines))
+
+	for _, line := range lines[start:end] {
+		b.WriteString(renderDiffLine(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.TextDiffPosition), start+1, end, len(lines))))
+	b.WriteString("\n")
+	b.WriteString(normalStyle.Render(help))
+
+	return b.String()
+}
+
+// renderDiffLine colors a single unified diff line
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "diff --git"),
+		strings.HasPrefix(line, "+++"),
+		strings.HasPrefix(line, "---"):
+		return diffHeaderStyle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return diffHunkStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffDeleteStyle.Render(line)
+	default:
+		return normalStyle.Render(line)
+	}
+}
+
+// renderStaging displays the pre-checkpoint file checklist
+func (r *Renderer) renderStaging(m models.Model) string {
+	var b strings.Builder
+
+	b.WriteString(normalStyle.Render(models.T(models.LabelStaging)))
+	b.WriteString("\n\n")
+
+	for i, file := range m.Status.ChangedFiles() {
+		prefix := "  "
+		if i == m.StagingCursor {
+			prefix = "▶ "
+		}
+
+		checkbox := "[ ]"
+		if m.IsFileSelected(file.Path) {
+			checkbox = "[x]"
+		}
+
+		line := fmt.Sprintf("%s%s %s %s", prefix, checkbox, fileMarker(file.Category), file.Path)
+		if i == m.StagingCursor {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(successStyle.Render(fmt.Sprintf(models.T(models.TextSelectedCount), len(m.SelectedFiles))))
+	b.WriteString("\n\n")
+	if staged := len(m.Status.Staged); staged > 0 {
+		b.WriteString(normalStyle.Render(fmt.Sprintf(models.T(models.TextStagedOnlyHint), staged)))
+		b.WriteString("\n")
+	}
+	b.WriteString(normalStyle.Render(models.T(models.HelpStaging)))
+
+	return b.String()
+}
+
+// fileMarker returns the status glyph used for a file category
+func fileMarker(category models.FileCategory) string {
+	switch category {
+	case models.FileStaged:
+		return "✓"
+	case models.FileModified:
+		return "•"
+	case models.FileDeleted:
+		return "✗"
+	default:
+		return "?"
+	}
+}
@@ -1,30 +1,83 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbletea"
 
+	"time-machine/internal/config"
 	"time-machine/internal/models"
 	"time-machine/internal/timekeeper"
 	"time-machine/internal/ui"
 )
 
 func main() {
+	// -C/--dir works in another directory without cd-ing there, like git -C
+	dir, args, err := parseGlobalFlags(os.Args[1:], os.Stderr)
+	if err != nil {
+		os.Exit(2)
+	}
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+
+	// Load settings, a broken config file falls back to defaults. The
+	// repository config sits at its root, wherever in it we start.
+	cfg, cfgErr := config.Load(timekeeper.RepositoryRoot(dir))
+
 	// Initialize services
-	gitService := timekeeper.NewService()
+	gitService := timekeeper.NewService(cfg)
+	if err := gitService.SetDir(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	renderer := ui.NewRenderer()
 
+	// Subcommands run without the TUI
+	if len(args) > 0 {
+		os.Exit(runCLI(gitService, args, os.Stdout, os.Stderr))
+	}
+
 	// Initialize model
 	m := models.Model{
 		Selected: 0,
+		Err:      cfgErr,
+		Profile:  gitService.ActiveProfile(),
+		// Read once, switching profiles keeps the layout
+		CompactStatus:  cfg.Status.Compact,
+		WrapNavigation: cfg.Navigation.Wrap,
+		PlainText:      !ui.SupportsEmoji(),
+		// Recent checkpoints read better as "2 часа назад", D shows full dates
+		RelativeDatesLimit:   time.Duration(cfg.UI.RelativeDatesDays) * 24 * time.Hour,
+		HistoryRelativeDates: cfg.UI.RelativeDatesDays > 0,
+	}
+	if cfg.UI.Emoji != nil {
+		m.PlainText = !*cfg.UI.Emoji
+	}
+
+	// A layout picked with the toggle wins over the config
+	state := config.LoadState()
+	if state.Compact != nil {
+		m.CompactStatus = *state.Compact
+	}
+	m.IdentityDismissed = state.IdentityDismissed
+	m.HideMenuHelp = state.HideMenuHelp
+	m.IncludeUntracked = gitService.IncludeUntracked()
+
+	// Remember the project for the recent repositories switcher
+	if root, err := gitService.RepositoryRoot(); err == nil {
+		_ = config.RememberRecent(root)
 	}
 
 	// Enable debug logging if DEBUG environment variable is set
+	var debugLog *os.File
 	if len(os.Getenv("DEBUG")) > 0 {
 		if f, err := tea.LogToFile("debug.log", "debug"); err == nil {
-			defer f.Close()
+			debugLog = f
 		}
 	}
 
@@ -34,17 +87,49 @@ func main() {
 		tea.WithAltScreen(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	// Forward progress of long operations to the loading screen
+	gitService.SetProgress(func(text string) {
+		p.Send(models.ProgressMsg{Text: text})
+	})
+	// and new journal entries to the journal
+	gitService.SetActivityFeed(func(entry models.ActivityEntry) {
+		p.Send(models.ActivityEntryMsg{Entry: entry})
+	})
+
+	_, err = p.Run()
+	shutdown(gitService, debugLog)
+	if err != nil {
 		fmt.Printf("Error: %v", err)
 		os.Exit(1)
 	}
 }
 
+// shutdown flushes what the session leaves behind before the process exits:
+// pending activity entries, the repository the user ended up in for the
+// recent list and the debug log
+func shutdown(gitService *timekeeper.Service, debugLog *os.File) {
+	// The screen is back to normal, so hooks report to the terminal
+	printHooks(gitService.RunHooks(config.HookBeforeQuit), os.Stdout, os.Stderr)
+
+	gitService.Shutdown()
+
+	if root, err := gitService.RepositoryRoot(); err == nil {
+		_ = config.RememberRecent(root)
+	}
+
+	if debugLog != nil {
+		debugLog.Sync()
+		debugLog.Close()
+	}
+}
+
 // App represents the Bubble Tea application
 type App struct {
 	gitService *timekeeper.Service
 	renderer   *ui.Renderer
 	model      models.Model
+	// confirmCheckpoint repeats the last checkpoint once its size is confirmed
+	confirmCheckpoint tea.Cmd
 }
 
 // NewApp creates a new application instance
@@ -58,7 +143,18 @@ func NewApp(gitService *timekeeper.Service, renderer *ui.Renderer, model models.
 
 // Init initializes the application
 func (a *App) Init() tea.Cmd {
-	return a.gitService.LoadStatus
+	return tea.Batch(a.gitService.LoadStatus, a.autoCheckpointTick())
+}
+
+// autoCheckpointTick schedules the next auto-save, nil when they are off
+func (a *App) autoCheckpointTick() tea.Cmd {
+	interval := a.gitService.AutoCheckpointInterval()
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return models.AutoCheckpointTickMsg{}
+	})
 }
 
 // Update handles user input and updates the model
@@ -67,16 +163,34 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case *models.GitStatus:
 		a.model.Status = msg
 		a.model.Loading = false
-		return a, nil
+		if files := msg.Files(); a.model.FilesSelected >= len(files) {
+			a.model.FilesSelected = max(len(files)-1, 0)
+		}
+		// Fetch behind the status's back, it shows the refs as they are
+		return a, a.gitService.RefreshRemote
+
+	case models.RemoteRefreshedMsg:
+		return a, a.gitService.LoadStatus
 
 	case models.ErrMsg:
-		a.model.Err = msg.Error
+		a.model.Err = timekeeper.Explain(msg.Error)
 		a.model.Loading = false
+		a.model.WorkDirGone = errors.Is(msg.Error, timekeeper.ErrWorkDirUnavailable)
+		a.model.SyncAfterCheckpoint = false
+		a.model.CheckpointNotice = ""
+		// A failed stats batch must not keep the next ones from being asked
+		a.model.HistoryStatsLoading = false
 		return a, nil
 
 	case models.StatusMsg:
 		a.model.Loading = false
-		return a, nil
+		a.model.Notice = msg.Text
+		a.model.Summary = msg.Summary
+		if a.model.StashMode {
+			// Stash actions stay on the list, it has to reflect the change
+			return a, tea.Batch(a.gitService.LoadStatus, a.gitService.ListStashes)
+		}
+		return a, a.gitService.LoadStatus
 
 	case models.GitInitializedMsg:
 		a.model.GitNotInitialized = false
@@ -86,21 +200,123 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case models.CheckpointCreatedMsg:
 		a.model.Loading = false
+		if msg.Success && a.model.SyncAfterCheckpoint {
+			// Chain the sync and report both results together
+			a.model.SyncAfterCheckpoint = false
+			a.model.CheckpointNotice = msg.Message
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Синхронизирую потоки...")
+			return a, tea.Batch(a.gitService.SyncWithRemote, a.runHooks(config.HookAfterCheckpoint))
+		}
+		a.model.SyncAfterCheckpoint = false
 		if msg.Success {
-			return a, a.gitService.LoadStatus
+			return a, tea.Batch(a.gitService.LoadStatus, a.runHooks(config.HookAfterCheckpoint))
+		}
+		a.model.Warning = msg.Message
+		return a, nil
+
+	case models.HooksMsg:
+		failures, outputs := hookReport(msg)
+		if len(failures) > 0 {
+			a.model.Warning = strings.Join(failures, "; ")
+		}
+		if len(outputs) > 0 {
+			a.model.Notice = strings.Join(outputs, "; ")
 		}
 		return a, nil
 
+	case models.ConfirmCheckpointMsg:
+		a.model.Loading = false
+		a.model.ConfirmFiles = msg.Files
+		a.model.ConfirmLimit = msg.Limit
+		return a, nil
+
 	case models.CheckpointsLoadedMsg:
 		a.model.Checkpoints = msg.Checkpoints
-		a.model.HistoryMode = true
-		a.model.HistorySelected = 0
+		a.model.HasMore = msg.HasMore
+		a.model.HistoryLoadingMore = false
+		a.model.HistoryShallow = msg.Shallow
+		a.model.HistoryBranch = msg.Branch
+		a.model.HistoryOffset = 0
+		a.model.HistoryFilter = ""
+		a.model.HistoryFiltering = false
+		a.model.HistoryCompareBase = ""
 		a.model.Loading = false
+		if msg.Canceled {
+			a.model.Warning = fmt.Sprintf(models.T(models.TextHistoryCanceled), len(msg.Checkpoints))
+		}
+		// Search filters the same list without showing the history
+		if !a.model.SearchMode {
+			a.model.HistoryMode = true
+			a.model.HistorySelected = 0
+		}
+		return a, tea.Batch(a.loadHistoryStats(), a.loadMoreHistory())
+
+	case models.MoreCheckpointsLoadedMsg:
+		// A page for a history reloaded meanwhile doesn't fit anymore
+		a.model.HistoryLoadingMore = false
+		if len(a.model.Checkpoints) == 0 || a.model.Checkpoints[len(a.model.Checkpoints)-1].Hash != msg.After {
+			return a, nil
+		}
+		a.model.Checkpoints = append(a.model.Checkpoints, msg.Checkpoints...)
+		a.model.HasMore = msg.HasMore
+		a.model.HistoryShallow = msg.Shallow
+		// A filter may still have found nothing, keep looking
+		return a, tea.Batch(a.loadHistoryStats(), a.loadMoreHistory())
+
+	case models.CheckpointStatsMsg:
+		a.model.HistoryStatsLoading = false
+		if a.model.HistoryStats == nil {
+			a.model.HistoryStats = make(map[string]models.DiffStat)
+		}
+		for hash, stat := range msg.Stats {
+			a.model.HistoryStats[hash] = stat
+		}
+		// The selection may have moved on while these were counted
+		return a, a.loadHistoryStats()
+
+	case models.NoteMsg:
+		for i := range a.model.Checkpoints {
+			if a.model.Checkpoints[i].Hash == msg.Hash {
+				a.model.Checkpoints[i].Note = msg.Note
+			}
+		}
+		if msg.Note != "" {
+			a.model.Notice = fmt.Sprintf(models.T(models.TextNoteSaved), msg.Hash)
+		} else {
+			a.model.Notice = fmt.Sprintf(models.T(models.TextNoteRemoved), msg.Hash)
+		}
+		return a, nil
+
+	case models.TaggedMsg:
+		for i := range a.model.Checkpoints {
+			if a.model.Checkpoints[i].Hash == msg.Hash {
+				a.model.Checkpoints[i].Tags = append(a.model.Checkpoints[i].Tags, msg.Tag)
+			}
+		}
+		a.model.Notice = fmt.Sprintf(models.T(models.TextTagged), msg.Hash, msg.Tag)
+		return a, nil
+
+	case models.PinnedMsg:
+		for i := range a.model.Checkpoints {
+			if a.model.Checkpoints[i].Hash == msg.Hash {
+				a.model.Checkpoints[i].Pinned = msg.Pinned
+			}
+		}
+		if msg.Pinned {
+			a.model.Notice = fmt.Sprintf(models.T(models.TextPinned), msg.Hash)
+		} else {
+			a.model.Notice = fmt.Sprintf(models.T(models.TextUnpinned), msg.Hash)
+		}
 		return a, nil
 
 	case models.RollbackMsg:
 		a.model.Loading = false
 		a.model.HistoryMode = false
+		a.model.Summary = msg.Summary
+		if msg.Backup != "" {
+			a.model.Notice = fmt.Sprintf(models.T(models.TextRollbackBackup), msg.Backup)
+		}
 		if msg.Success {
 			return a, a.gitService.LoadStatus
 		}
@@ -111,6 +327,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.model.DescriptionMode = true
 		a.model.DescriptionInput = ""
 		a.model.Suggestions = msg.Suggestions
+		a.model.EstimatedSize = msg.EstimatedSize
 		return a, nil
 
 	case models.GitNotInitializedMsg:
@@ -121,15 +338,170 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case models.SyncMsg:
 		a.model.Loading = false
+		a.model.Summary = msg.Summary
+		if a.model.CheckpointNotice != "" {
+			msg.Message = a.model.CheckpointNotice + " · " + msg.Message
+			a.model.CheckpointNotice = ""
+			if msg.Success {
+				a.model.Notice = msg.Message
+			}
+		}
 		if msg.Success {
-			return a, a.gitService.LoadStatus
+			a.model.SyncResult = &msg
+			if msg.Backup != "" {
+				a.model.Notice = fmt.Sprintf(models.T(models.TextTakeTheirsBackup), msg.Backup)
+			}
+			return a, tea.Batch(a.gitService.LoadStatus, a.runHooks(config.HookAfterSync))
 		}
 		// Store sync error message to display
 		a.model.SyncMessage = msg.Message
 		a.model.ShowSyncMessage = true
 		return a, nil
 
+	case models.SquashCandidatesMsg:
+		a.model.Loading = false
+		if len(msg.Messages) < 2 {
+			a.model.Warning = models.T(models.TextNothingToSquash)
+			return a, nil
+		}
+		// Name the squash in description mode, offering the old subjects as suggestions
+		a.model.SquashMode = true
+		a.model.SquashCount = len(msg.Messages)
+		a.model.DescriptionMode = true
+		a.model.DescriptionInput = ""
+		a.model.Suggestions = msg.Messages
+		a.model.EstimatedSize = 0
+		return a, nil
+
+	case models.ProgressMsg:
+		if a.model.Loading {
+			a.model.LoadingText = msg.Text
+		}
+		return a, nil
+
+	case models.DiffMsg:
+		a.model.Loading = false
+		a.model.DiffMode = true
+		a.model.DiffTitle = msg.Title
+		a.model.DiffPatch = msg.Patch
+		a.model.DiffWorkingTree = msg.WorkingTree
+		a.model.DiffOffset = 0
+		return a, nil
+
+	case models.ShellExitedMsg:
+		// Anything could have changed while we were away
+		a.model.Loading = false
+		if msg.Err != nil {
+			a.model.Warning = fmt.Sprintf(models.T(models.TextShellFailed), msg.Err)
+		}
+		return a, a.gitService.LoadStatus
+
+	case models.BranchesLoadedMsg:
+		a.model.Loading = false
+		a.model.BranchMode = true
+		a.model.BranchCreating = false
+		a.model.Branches = msg.Branches
+		a.model.BranchSelected = 0
+		for i, branch := range msg.Branches {
+			if branch.Current {
+				a.model.BranchSelected = i
+			}
+		}
+		return a, nil
+
+	case models.StashesLoadedMsg:
+		a.model.Loading = false
+		if !a.model.StashMode {
+			a.model.StashSelected = 0
+		}
+		a.model.StashMode = true
+		a.model.Stashes = msg.Entries
+		a.model.StashSelected = max(min(a.model.StashSelected, len(msg.Entries)-1), 0)
+		return a, nil
+
+	case models.ActivityLoadedMsg:
+		a.model.Loading = false
+		a.model.ActivityMode = true
+		a.model.Activity = msg.Entries
+		a.model.ActivitySelected = 0
+		return a, nil
+
+	case models.ActivityEntryMsg:
+		a.model.RecentActivity = append([]models.ActivityEntry{msg.Entry}, a.model.RecentActivity...)
+		if len(a.model.RecentActivity) > models.RecentActivityLimit {
+			a.model.RecentActivity = a.model.RecentActivity[:models.RecentActivityLimit]
+		}
+		// An open journal tails the log and jumps to the newest entry
+		if a.model.ActivityMode {
+			a.model.Activity = append([]models.ActivityEntry{msg.Entry}, a.model.Activity...)
+			a.model.ActivitySelected = 0
+		}
+		return a, nil
+
+	case models.RepositoryOpenedMsg:
+		// Commands read the directory and config of the service, so it
+		// switches here rather than in one of them
+		switched := a.gitService.UseRepository(msg.Path)
+		return a, func() tea.Msg { return switched }
+
+	case models.RepositorySwitchedMsg:
+		a.model = models.Model{
+			Width:                a.model.Width,
+			Height:               a.model.Height,
+			CompactStatus:        a.model.CompactStatus,
+			PlainText:            a.model.PlainText,
+			WrapNavigation:       a.model.WrapNavigation,
+			RelativeDatesLimit:   a.model.RelativeDatesLimit,
+			HistoryRelativeDates: a.model.HistoryRelativeDates,
+			IdentityDismissed:    a.model.IdentityDismissed,
+			HideMenuHelp:         a.model.HideMenuHelp,
+			IncludeUntracked:     a.gitService.IncludeUntracked(),
+			Profile:              msg.Profile,
+			Err:                  msg.ConfigErr,
+			Notice:               fmt.Sprintf(models.T(models.TextRepositorySwitched), msg.Path),
+		}
+		return a, a.gitService.LoadStatus
+
+	case models.AutoCheckpointTickMsg:
+		// Never save behind the back of someone in the middle of something
+		if !a.model.OnMainScreen() || a.model.Status == nil || a.model.Status.IsClean {
+			return a, a.autoCheckpointTick()
+		}
+		return a, tea.Batch(a.autoCheckpointTick(), a.gitService.AutoCheckpoint)
+
+	case models.RollbackPreviewMsg:
+		a.model.Loading = false
+		a.model.RollbackPreview = &msg.Preview
+		return a, nil
+
+	case models.CheckpointResolvedMsg:
+		a.model.Loading = false
+		a.model.RefMode = false
+		a.model.RefTarget = &msg.Checkpoint
+		a.model.RefSelected = 0
+		return a, nil
+
+	case models.ConflictChoiceMsg:
+		a.model.Loading = false
+		a.model.ConflictMode = true
+		a.model.ConflictSelected = 0
+		a.model.ConflictReason = msg.Reason
+		a.model.ConflictOverwrites = msg.Overwrites
+		a.model.ConflictCanForce = msg.CanForce
+		if !msg.CanForce {
+			// Without keeping the local version, default to touching nothing
+			a.model.ConflictSelected = len(a.model.ConflictOptions()) - 1
+		}
+		return a, nil
+
+	case tea.WindowSizeMsg:
+		a.model.Width = msg.Width
+		a.model.Height = msg.Height
+		return a, nil
+
 	case tea.KeyMsg:
+		// A key press starts something new, earlier steps no longer matter
+		a.model.RecentActivity = nil
 		return a.handleKeyMsg(msg)
 	}
 
@@ -144,6 +516,26 @@ func (a *App) View() string {
 // handleKeyMsg handles keyboard input
 func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if a.model.Loading {
+		// Long loads can be given up on
+		if msg.Type == tea.KeyEscape {
+			a.gitService.Cancel()
+		}
+		return a, nil
+	}
+
+	// Nothing works without a working directory, only quitting is left
+	if a.model.WorkDirGone {
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			a.model.Quitting = true
+			return a, tea.Quit
+		}
+		return a, nil
+	}
+
+	// The recap of a destructive operation eats the first key so it gets read
+	if a.model.Summary != nil {
+		a.model.Summary = nil
 		return a, nil
 	}
 
@@ -152,13 +544,95 @@ func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.model.ShowSyncMessage = false
 		a.model.SyncMessage = ""
 	}
+	a.model.SyncResult = nil
+	a.model.Notice = ""
+	a.model.Warning = ""
+
+	if a.model.RollbackPreview != nil {
+		return a.handleRollbackPreviewInput(msg)
+	}
+
+	if a.model.ConfirmFiles > 0 {
+		return a.handleConfirmCheckpointInput(msg)
+	}
+
+	if a.model.ConflictMode {
+		return a.handleConflictInput(msg)
+	}
+
+	if a.model.ProfileMode {
+		return a.handleProfileInput(msg)
+	}
+
+	if a.model.ActivityMode {
+		return a.handleActivityInput(msg)
+	}
+
+	if a.model.StashMode {
+		return a.handleStashInput(msg)
+	}
+
+	if a.model.BranchMode {
+		return a.handleBranchInput(msg)
+	}
+
+	if a.model.RefTarget != nil {
+		return a.handleRefActionInput(msg)
+	}
+
+	if a.model.RefMode {
+		return a.handleRefInput(msg)
+	}
+
+	if a.model.SearchMode {
+		return a.handleSearchInput(msg)
+	}
+
+	if a.model.IdentityMode {
+		return a.handleIdentityInput(msg)
+	}
+
+	if a.model.RecentMode {
+		return a.handleRecentInput(msg)
+	}
+
+	if a.model.BlameMode {
+		return a.handleBlameInput(msg)
+	}
+
+	if a.model.NoteMode {
+		return a.handleNoteInput(msg)
+	}
+
+	if a.model.BranchFromMode {
+		return a.handleBranchFromInput(msg)
+	}
+
+	if a.model.TagMode {
+		return a.handleTagInput(msg)
+	}
+
+	if a.model.DiffMode {
+		return a.handleDiffInput(msg)
+	}
 
 	if a.model.DescriptionMode {
 		return a.handleDescriptionInput(msg)
 	}
 
 	if a.model.HistoryMode {
-		return a.handleHistoryInput(msg)
+		// Whatever moved the selection or narrowed the list, older
+		// checkpoints are loaded once it nears their end
+		model, cmd := a.handleHistoryInput(msg)
+		return model, tea.Batch(cmd, a.loadMoreHistory())
+	}
+
+	if a.model.StagingMode {
+		return a.handleStagingInput(msg)
+	}
+
+	if a.model.FilesMode {
+		return a.handleFilesInput(msg)
 	}
 
 	// Handle Escape key using Type for better reliability
@@ -181,11 +655,15 @@ func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "up", "k":
 		if a.model.Selected > 0 {
 			a.model.Selected--
+		} else if a.model.WrapNavigation {
+			a.model.Selected = len(a.model.GetMenuItems()) - 1
 		}
 
 	case "down", "j":
-		if a.model.Selected < len(models.GetMenuItems())-1 {
+		if a.model.Selected < len(a.model.GetMenuItems())-1 {
 			a.model.Selected++
+		} else if a.model.WrapNavigation {
+			a.model.Selected = 0
 		}
 
 	case "enter", " ":
@@ -195,100 +673,169 @@ func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Hotkeys for quick actions
 	case "c":
 		// Create checkpoint shortcut
-		a.model.Selected = 0
-		return a, a.handleMenuSelection()
+		return a, a.selectMenuItem(models.MenuCreateCheckpoint)
 
 	case "h":
 		// View history shortcut
-		a.model.Selected = 1
-		return a, a.handleMenuSelection()
+		return a, a.selectMenuItem(models.MenuViewHistory)
 
 	case "r":
 		// Rollback shortcut
-		a.model.Selected = 2
-		return a, a.handleMenuSelection()
+		return a, a.selectMenuItem(models.MenuRollback)
 
 	case "s":
 		// Sync shortcut
-		a.model.Selected = 3
-		return a, a.handleMenuSelection()
-	}
+		return a, a.selectMenuItem(models.MenuSync)
 
-	return a, nil
-}
+	case "p":
+		// Save and sync shortcut
+		return a, a.selectMenuItem(models.MenuSaveAndSync)
 
-// handleDescriptionInput handles input when in description mode
-func (a *App) handleDescriptionInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle number keys for quick selection first
-	if len(msg.Runes) == 1 {
-		r := msg.Runes[0]
-		if r >= '1' && r <= '9' {
-			index := int(r - '1')
-			if index < len(a.model.Suggestions) {
-				a.model.DescriptionInput = a.model.Suggestions[index]
-				return a, nil
+	case "m":
+		// Drop a marker checkpoint
+		return a, a.selectMenuItem(models.MenuCreateMarker)
+
+	case "d":
+		// Review uncommitted changes
+		return a, a.selectMenuItem(models.MenuViewChanges)
+
+	case "f":
+		// Browse changed files
+		if a.model.Status != nil && !a.model.GitNotInitialized {
+			a.model.FilesMode = true
+			a.model.FilesSelected = 0
+		}
+
+	case "g":
+		// Jump to a commit by ref
+		if a.model.Status != nil && !a.model.GitNotInitialized {
+			a.model.RefMode = true
+			a.model.RefInput = ""
+		}
+
+	case "i":
+		// Set the missing git identity right here
+		if a.model.ShowIdentityBanner() {
+			a.model.IdentityMode = true
+			a.model.IdentityName = ""
+			a.model.IdentityInput = ""
+		}
+
+	case "n":
+		// Undo the rollbacks and go back to the present
+		return a, a.selectMenuItem(models.MenuReturnToPresent)
+
+	case "x":
+		// Hide the missing identity banner for good
+		if a.model.ShowIdentityBanner() {
+			a.model.IdentityDismissed = true
+			state := config.LoadState()
+			state.IdentityDismissed = true
+			if err := config.SaveState(state); err != nil {
+				a.model.Warning = fmt.Sprintf(models.T(models.TextStateNotSaved), err)
 			}
 		}
-	}
 
-	switch msg.Type {
-	case tea.KeyEscape:
-		// Exit description mode
-		a.model.DescriptionMode = false
-		a.model.DescriptionInput = ""
-		return a, nil
+	case "/":
+		// Find a checkpoint by text and roll back to it
+		if a.model.Status != nil && !a.model.GitNotInitialized {
+			a.model.SearchMode = true
+			a.model.SearchInput = ""
+			a.model.SearchSelected = 0
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Вспоминаем былое...")
+			// Search looks through the whole history, not a page of it
+			return a, func() tea.Msg {
+				return a.gitService.LoadCheckpoints(0)
+			}
+		}
 
-	case tea.KeyEnter:
-		// Create checkpoint with description
-		description := a.model.DescriptionInput
-		if description == "" {
-			// Use default if empty
-			description = "Сейв без описания"
+	case "?":
+		// Hide or bring back the menu explanations and remember the choice
+		a.model.HideMenuHelp = !a.model.HideMenuHelp
+		state := config.LoadState()
+		state.HideMenuHelp = a.model.HideMenuHelp
+		if err := config.SaveState(state); err != nil {
+			a.model.Warning = fmt.Sprintf(models.T(models.TextStateNotSaved), err)
 		}
-		a.model.DescriptionMode = false
-		a.model.Loading = true
-		a.model.LoadingText = "Сейвлю вайб..."
-		return a, func() tea.Msg {
-			return a.gitService.CreateCheckpoint(description)
+
+	case "w":
+		// Switch to a recently opened repository
+		a.model.RecentMode = true
+		a.model.Recent = config.LoadRecent()
+		a.model.RecentSelected = 0
+
+	case "z":
+		// Open the stash list
+		if a.model.Status != nil && !a.model.GitNotInitialized {
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Достаю отложенное...")
+			return a, a.gitService.ListStashes
 		}
 
-	case tea.KeyBackspace:
-		if len(a.model.DescriptionInput) > 0 {
-			a.model.DescriptionInput = a.model.DescriptionInput[:len(a.model.DescriptionInput)-1]
+	case "u":
+		// Take back the last checkpoint, its changes stay in the files
+		if a.model.Status != nil && !a.model.GitNotInitialized {
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Отменяю сейв...")
+			return a, a.gitService.UndoLastCheckpoint
 		}
-		return a, nil
 
-	case tea.KeyRunes:
-		// Add typed characters (but not numbers since we handled them above)
-		r := msg.Runes[0]
-		if r < '1' || r > '9' {
-			a.model.DescriptionInput += string(msg.Runes)
+	case "b":
+		// Switch or create a branch
+		return a, a.selectMenuItem(models.MenuBranches)
+
+	case "L":
+		// Open the activity journal
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Листаю журнал...")
+		return a, a.gitService.LoadActivity
+
+	case "o":
+		// Switch the config profile
+		profiles := a.gitService.Profiles()
+		if len(profiles) == 0 {
+			a.model.Warning = models.T(models.TextNoProfiles)
+			return a, nil
+		}
+		a.model.ProfileMode = true
+		a.model.Profiles = append([]string{""}, profiles...)
+		a.model.ProfileSelected = 0
+		for i, name := range a.model.Profiles {
+			if name == a.model.Profile {
+				a.model.ProfileSelected = i
+			}
 		}
-		return a, nil
-	}
 
-	switch msg.String() {
-	case "ctrl+c":
-		a.model.Quitting = true
-		return a, tea.Quit
+	case "!":
+		// Escape hatch for everything the tool doesn't do
+		return a, a.openShell()
+
+	case "a":
+		// Show the whole repository or only the launch directory again
+		if a.model.Status != nil && a.model.Status.Scope != "" {
+			a.model.WholeRepo = !a.model.WholeRepo
+		}
+
+	case "v":
+		// Switch between the compact and full status and remember the choice
+		a.model.CompactStatus = !a.model.CompactStatus
+		compact := a.model.CompactStatus
+		state := config.LoadState()
+		state.Compact = &compact
+		if err := config.SaveState(state); err != nil {
+			a.model.Warning = fmt.Sprintf(models.T(models.TextStateNotSaved), err)
+		}
 	}
 
 	return a, nil
 }
 
-// handleHistoryInput handles input when in history mode
-func (a *App) handleHistoryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle Escape key using Type for better reliability
+// handleConflictInput handles picking a resolution for a rejected pull
+func (a *App) handleConflictInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEscape:
-		// Escape goes back to main menu
-		a.model.HistoryMode = false
-		return a, nil
-
-	case tea.KeyBackspace:
-		// Backspace also goes back to main menu (more intuitive)
-		a.model.HistoryMode = false
-		return a, nil
+		return a, a.resolveConflict(models.ConflictManual)
 	}
 
 	switch msg.String() {
@@ -296,43 +843,1395 @@ func (a *App) handleHistoryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.model.Quitting = true
 		return a, tea.Quit
 
-	case "q":
-		// 'q' now quits from history mode too for consistency
-		a.model.Quitting = true
-		return a, tea.Quit
-
 	case "esc", "escape":
 		// Fallback for terminals where Type detection doesn't work
-		a.model.HistoryMode = false
-		return a, nil
+		return a, a.resolveConflict(models.ConflictManual)
 
 	case "up", "k":
-		if a.model.HistorySelected > 0 {
-			a.model.HistorySelected--
+		if a.model.ConflictSelected > 0 {
+			a.model.ConflictSelected--
 		}
 
 	case "down", "j":
-		if a.model.HistorySelected < len(a.model.Checkpoints)-1 {
-			a.model.HistorySelected++
+		if a.model.ConflictSelected < len(a.model.ConflictOptions())-1 {
+			a.model.ConflictSelected++
 		}
 
 	case "enter", " ":
-		if a.model.HistorySelected < len(a.model.Checkpoints) {
-			checkpoint := a.model.Checkpoints[a.model.HistorySelected]
-			a.model.Loading = true
-			a.model.LoadingText = "Возвращаю старый вайб..."
-			return a, func() tea.Msg {
-				return a.gitService.RollbackToCheckpoint(checkpoint.Hash)
-			}
-		}
+		return a, a.resolveConflict(a.model.ConflictOptions()[a.model.ConflictSelected])
 	}
 
 	return a, nil
 }
 
-// handleMenuSelection processes the selected menu item
-func (a *App) handleMenuSelection() tea.Cmd {
-	menuItems := a.model.GetMenuItems()
+// resolveConflict leaves the conflict screen and applies the choice
+func (a *App) resolveConflict(choice models.ConflictChoice) tea.Cmd {
+	a.model.ConflictMode = false
+	a.model.ConflictReason = ""
+	a.model.ConflictOverwrites = nil
+	a.model.ConflictCanForce = false
+	a.model.Loading = true
+	a.model.LoadingText = models.T("Разруливаю конфликт...")
+	return func() tea.Msg {
+		return a.gitService.ResolveConflict(choice)
+	}
+}
+
+// handleProfileInput handles picking the active config profile
+func (a *App) handleProfileInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.ProfileMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.ProfileMode = false
+		return a, nil
+
+	case "up", "k":
+		if a.model.ProfileSelected > 0 {
+			a.model.ProfileSelected--
+		}
+
+	case "down", "j":
+		if a.model.ProfileSelected < len(a.model.Profiles)-1 {
+			a.model.ProfileSelected++
+		}
+
+	case "enter", " ":
+		name := a.model.Profiles[a.model.ProfileSelected]
+		a.model.ProfileMode = false
+		if err := a.gitService.UseProfile(name); err != nil {
+			a.model.Err = err
+			return a, nil
+		}
+		a.model.Profile = name
+		if name == "" {
+			name = models.T(models.TextNoProfile)
+		}
+		a.model.Notice = fmt.Sprintf(models.T(models.TextProfileActive), name)
+		// Noise patterns may differ between profiles
+		return a, a.gitService.LoadStatus
+	}
+
+	return a, nil
+}
+
+// handleActivityInput handles scrolling the activity journal
+func (a *App) handleActivityInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.ActivityMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.ActivityMode = false
+		return a, nil
+
+	case "up", "k":
+		if a.model.ActivitySelected > 0 {
+			a.model.ActivitySelected--
+		}
+
+	case "down", "j":
+		if a.model.ActivitySelected < len(a.model.Activity)-1 {
+			a.model.ActivitySelected++
+		}
+	}
+
+	return a, nil
+}
+
+// handleRollbackPreviewInput confirms or cancels the previewed rollback
+func (a *App) handleRollbackPreviewInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "enter", "y":
+		// Losing work takes a deliberate y, a second Enter mashed through
+		// the history isn't enough
+		if preview := a.model.RollbackPreview; msg.String() == "enter" && preview.Unsaved > 0 && !preview.AutoSaved {
+			return a, nil
+		}
+		hash := a.model.RollbackPreview.Hash
+		a.model.RollbackPreview = nil
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Возвращаю старый вайб...")
+		return a, func() tea.Msg {
+			return a.gitService.RollbackToCheckpoint(hash)
+		}
+
+	case "r":
+		// Keeps the later checkpoints, so it needs no extra confirmation
+		hash := a.model.RollbackPreview.Hash
+		a.model.RollbackPreview = nil
+		a.model.HistoryMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Откатываю, сохраняя историю...")
+		return a, func() tea.Msg {
+			return a.gitService.RevertToCheckpoint(hash)
+		}
+
+	case "esc", "escape", "n", "q":
+		a.model.RollbackPreview = nil
+	}
+
+	return a, nil
+}
+
+// handleConfirmCheckpointInput confirms or cancels a checkpoint touching
+// more files than the configured limit
+func (a *App) handleConfirmCheckpointInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "enter", "y":
+		a.model.ConfirmFiles = 0
+		if a.confirmCheckpoint != nil {
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Сейвлю вайб...")
+			return a, a.confirmCheckpoint
+		}
+
+	case "esc", "escape", "n", "q":
+		a.model.ConfirmFiles = 0
+		a.model.SyncAfterCheckpoint = false
+		a.confirmCheckpoint = nil
+		a.model.Notice = models.T(models.TextCheckpointCanceled)
+	}
+
+	return a, nil
+}
+
+// handleStashInput handles the stash list
+func (a *App) handleStashInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.StashMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.StashMode = false
+		return a, nil
+
+	case "up", "k":
+		if a.model.StashSelected > 0 {
+			a.model.StashSelected--
+		}
+
+	case "down", "j":
+		if a.model.StashSelected < len(a.model.Stashes)-1 {
+			a.model.StashSelected++
+		}
+
+	case "a", "p", "x":
+		if len(a.model.Stashes) == 0 {
+			return a, nil
+		}
+		index := a.model.Stashes[a.model.StashSelected].Index
+		a.model.Loading = true
+		switch msg.String() {
+		case "a":
+			a.model.LoadingText = models.T("Возвращаю отложенное...")
+			return a, func() tea.Msg { return a.gitService.ApplyStash(index, false) }
+		case "p":
+			a.model.LoadingText = models.T("Возвращаю отложенное...")
+			return a, func() tea.Msg { return a.gitService.ApplyStash(index, true) }
+		default:
+			a.model.LoadingText = models.T("Удаляю отложенное...")
+			return a, func() tea.Msg { return a.gitService.DropStash(index) }
+		}
+	}
+
+	return a, nil
+}
+
+// handleBranchInput handles the branch picker and typing the name of a new
+// branch
+func (a *App) handleBranchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.model.BranchCreating {
+		switch msg.Type {
+		case tea.KeyEscape:
+			a.model.BranchCreating = false
+
+		case tea.KeyCtrlC:
+			a.model.Quitting = true
+			return a, tea.Quit
+
+		case tea.KeyEnter:
+			name := strings.TrimSpace(a.model.BranchInput)
+			if name == "" {
+				return a, nil
+			}
+			a.model.BranchMode = false
+			a.model.BranchCreating = false
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Создаю ветку...")
+			return a, func() tea.Msg { return a.gitService.CreateBranch(name) }
+
+		case tea.KeyBackspace:
+			a.model.BranchInput = trimLastRune(a.model.BranchInput)
+
+		case tea.KeyRunes:
+			a.model.BranchInput += string(msg.Runes)
+		}
+		return a, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.BranchMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.BranchMode = false
+
+	case "up", "k":
+		if a.model.BranchSelected > 0 {
+			a.model.BranchSelected--
+		}
+
+	case "down", "j":
+		if a.model.BranchSelected < len(a.model.Branches)-1 {
+			a.model.BranchSelected++
+		}
+
+	case "n":
+		a.model.BranchCreating = true
+		a.model.BranchInput = ""
+
+	case "enter":
+		if len(a.model.Branches) == 0 {
+			return a, nil
+		}
+		name := a.model.Branches[a.model.BranchSelected].Name
+		a.model.BranchMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Переключаю ветку...")
+		return a, func() tea.Msg { return a.gitService.SwitchBranch(name) }
+	}
+
+	return a, nil
+}
+
+// handleRefInput handles typing the ref to jump to
+func (a *App) handleRefInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.RefMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyEnter:
+		rev := strings.TrimSpace(a.model.RefInput)
+		if rev == "" {
+			return a, nil
+		}
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Ищу сейв...")
+		return a, func() tea.Msg {
+			return a.gitService.ResolveCheckpoint(rev)
+		}
+
+	case tea.KeyBackspace:
+		a.model.RefInput = trimLastRune(a.model.RefInput)
+
+	case tea.KeyRunes:
+		a.model.RefInput += string(msg.Runes)
+	}
+
+	return a, nil
+}
+
+// handleSearchInput handles typing a search and picking the checkpoint to
+// roll back to
+func (a *App) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	results := a.model.SearchResults()
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.SearchMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyUp:
+		if a.model.SearchSelected > 0 {
+			a.model.SearchSelected--
+		}
+
+	case tea.KeyDown:
+		if a.model.SearchSelected < len(results)-1 {
+			a.model.SearchSelected++
+		}
+
+	case tea.KeyEnter:
+		if a.model.SearchSelected >= len(results) {
+			return a, nil
+		}
+		hash := results[a.model.SearchSelected].Hash
+		a.model.SearchMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Прикидываю последствия...")
+		return a, func() tea.Msg {
+			return a.gitService.RollbackPreview(hash)
+		}
+
+	case tea.KeyBackspace:
+		if a.model.SearchInput != "" {
+			a.model.SearchInput = trimLastRune(a.model.SearchInput)
+			a.model.SearchSelected = 0
+		}
+
+	case tea.KeyRunes, tea.KeySpace:
+		a.model.SearchInput += string(msg.Runes)
+		a.model.SearchSelected = 0
+	}
+
+	return a, nil
+}
+
+// handleBlameInput handles typing the file to blame at a checkpoint
+func (a *App) handleBlameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.BlameMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyEnter:
+		path := strings.TrimSpace(a.model.BlameInput)
+		if path == "" {
+			return a, nil
+		}
+		hash := a.model.BlameHash
+		a.model.BlameMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Вспоминаю, кто что писал...")
+		return a, func() tea.Msg {
+			return a.gitService.BlameAtCommit(hash, path)
+		}
+
+	case tea.KeyBackspace:
+		a.model.BlameInput = trimLastRune(a.model.BlameInput)
+
+	case tea.KeyRunes, tea.KeySpace:
+		a.model.BlameInput += string(msg.Runes)
+	}
+
+	return a, nil
+}
+
+// handleNoteInput handles editing the note of a checkpoint
+func (a *App) handleNoteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.NoteMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyEnter:
+		hash, note := a.model.NoteHash, a.model.NoteInput
+		a.model.NoteMode = false
+		return a, func() tea.Msg {
+			return a.gitService.AddNote(hash, note)
+		}
+
+	case tea.KeyBackspace:
+		a.model.NoteInput = trimLastRune(a.model.NoteInput)
+
+	case tea.KeyRunes, tea.KeySpace:
+		a.model.NoteInput += string(msg.Runes)
+	}
+
+	return a, nil
+}
+
+// handleBranchFromInput handles typing the name of a branch starting at a
+// checkpoint from the history
+func (a *App) handleBranchFromInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.BranchFromMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyEnter:
+		name := strings.TrimSpace(a.model.BranchFromInput)
+		if name == "" {
+			return a, nil
+		}
+		hash := a.model.BranchFromHash
+		a.model.BranchFromMode = false
+		a.model.HistoryMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Создаю ветку...")
+		return a, func() tea.Msg {
+			return a.gitService.CreateBranchFromCheckpoint(hash, name)
+		}
+
+	case tea.KeyBackspace:
+		a.model.BranchFromInput = trimLastRune(a.model.BranchFromInput)
+
+	case tea.KeyRunes:
+		a.model.BranchFromInput += string(msg.Runes)
+	}
+
+	return a, nil
+}
+
+// handleTagInput handles typing the name of a tag for a checkpoint from
+// the history
+func (a *App) handleTagInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.TagMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyEnter:
+		name := strings.TrimSpace(a.model.TagInput)
+		if name == "" {
+			return a, nil
+		}
+		hash := a.model.TagHash
+		a.model.TagMode = false
+		return a, func() tea.Msg {
+			return a.gitService.TagCheckpoint(hash, name)
+		}
+
+	case tea.KeyBackspace:
+		a.model.TagInput = trimLastRune(a.model.TagInput)
+
+	case tea.KeyRunes:
+		a.model.TagInput += string(msg.Runes)
+	}
+
+	return a, nil
+}
+
+// handleRecentInput handles picking a recent repository to switch to
+func (a *App) handleRecentInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.RecentMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.RecentMode = false
+		return a, nil
+
+	case "up", "k":
+		if a.model.RecentSelected > 0 {
+			a.model.RecentSelected--
+		}
+
+	case "down", "j":
+		if a.model.RecentSelected < len(a.model.Recent)-1 {
+			a.model.RecentSelected++
+		}
+
+	case "enter", " ":
+		if a.model.RecentSelected < len(a.model.Recent) {
+			path := a.model.Recent[a.model.RecentSelected]
+			a.model.RecentMode = false
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Открываю проект...")
+			return a, func() tea.Msg {
+				return a.gitService.SwitchRepository(path)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// handleIdentityInput handles typing the name and then the email to store
+// in the global git config
+func (a *App) handleIdentityInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.IdentityMode = false
+		return a, nil
+
+	case tea.KeyCtrlC:
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case tea.KeyEnter:
+		input := strings.TrimSpace(a.model.IdentityInput)
+		if input == "" {
+			return a, nil
+		}
+		if a.model.IdentityName == "" {
+			a.model.IdentityName = input
+			a.model.IdentityInput = ""
+			return a, nil
+		}
+		name := a.model.IdentityName
+		a.model.IdentityMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Запоминаю тебя...")
+		return a, func() tea.Msg {
+			return a.gitService.SetIdentity(name, input)
+		}
+
+	case tea.KeyBackspace:
+		a.model.IdentityInput = trimLastRune(a.model.IdentityInput)
+
+	case tea.KeyRunes, tea.KeySpace:
+		a.model.IdentityInput += string(msg.Runes)
+	}
+
+	return a, nil
+}
+
+// handleRefActionInput handles acting on the commit found by a typed ref
+func (a *App) handleRefActionInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.RefTarget = nil
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.RefTarget = nil
+		return a, nil
+
+	case "up", "k":
+		if a.model.RefSelected > 0 {
+			a.model.RefSelected--
+		}
+
+	case "down", "j":
+		if a.model.RefSelected < len(models.RefActions)-1 {
+			a.model.RefSelected++
+		}
+
+	case "enter", " ":
+		hash := a.model.RefTarget.Hash
+		a.model.RefTarget = nil
+		a.model.Loading = true
+		switch models.RefActions[a.model.RefSelected].Action {
+		case models.RefRollback:
+			a.model.LoadingText = models.T("Прикидываю последствия...")
+			return a, func() tea.Msg {
+				return a.gitService.RollbackPreview(hash)
+			}
+		case models.RefDiff:
+			a.model.LoadingText = models.T("Собираю изменения...")
+			return a, func() tea.Msg {
+				return a.gitService.CheckpointDiff(hash)
+			}
+		case models.RefHistory:
+			branch := strings.TrimSpace(a.model.RefInput)
+			a.model.LoadingText = models.T("Вспоминаем былое...")
+			return a, func() tea.Msg {
+				return a.gitService.LoadBranchCheckpoints(branch, timekeeper.HistoryPageLimit)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// openShell suspends the TUI and runs a shell in the repository
+func (a *App) openShell() tea.Cmd {
+	cmd, err := a.gitService.ShellCommand()
+	if err != nil {
+		return func() tea.Msg { return models.ErrMsg{Error: err} }
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return models.ShellExitedMsg{Err: err}
+	})
+}
+
+// handleDiffInput handles scrolling in the diff viewer
+func (a *App) handleDiffInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	lineCount := strings.Count(a.model.DiffPatch, "\n")
+	pageSize := a.model.DiffPageSize()
+	maxOffset := max(lineCount-pageSize, 0)
+
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.DiffMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.DiffMode = false
+		return a, nil
+
+	case "up", "k":
+		a.model.DiffOffset--
+
+	case "down", "j":
+		a.model.DiffOffset++
+
+	case "pgup", "b":
+		a.model.DiffOffset -= pageSize
+
+	case "pgdown", " ":
+		a.model.DiffOffset += pageSize
+
+	case "home", "g":
+		a.model.DiffOffset = 0
+
+	case "end", "G":
+		a.model.DiffOffset = maxOffset
+
+	case "s", "u":
+		// Add the file on screen to the next checkpoint or take it out
+		file := a.model.DiffFile()
+		if !a.model.DiffWorkingTree || file == "" {
+			return a, nil
+		}
+		if msg.String() == "s" {
+			return a, func() tea.Msg {
+				return a.gitService.StagePaths([]string{file})
+			}
+		}
+		return a, func() tea.Msg {
+			return a.gitService.UnstagePaths([]string{file})
+		}
+	}
+
+	a.model.DiffOffset = min(max(a.model.DiffOffset, 0), maxOffset)
+	return a, nil
+}
+
+// handleStagingInput handles input on the pre-checkpoint staging screen
+func (a *App) handleStagingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	files := a.model.Status.ChangedFiles()
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		a.model.StagingMode = false
+		a.model.SelectedFiles = nil
+		a.model.SyncAfterCheckpoint = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.StagingMode = false
+		a.model.SelectedFiles = nil
+		a.model.SyncAfterCheckpoint = false
+		return a, nil
+
+	case "up", "k":
+		if a.model.StagingCursor > 0 {
+			a.model.StagingCursor--
+		}
+
+	case "down", "j":
+		if a.model.StagingCursor < len(files)-1 {
+			a.model.StagingCursor++
+		}
+
+	case " ":
+		if a.model.StagingCursor < len(files) {
+			a.model.ToggleFile(files[a.model.StagingCursor].Path)
+		}
+
+	case "s":
+		// Checkpoint the index exactly as it was staged
+		if len(a.model.Status.Staged) == 0 {
+			return a, nil
+		}
+		a.model.StagedOnly = true
+		a.model.SelectedFiles = append([]string(nil), a.model.Status.Staged...)
+		a.model.StagingMode = false
+		return a, a.enterDescriptionMode()
+
+	case "a":
+		// Select everything, or clear the selection when everything is selected
+		if len(a.model.SelectedFiles) == len(files) {
+			a.model.SelectedFiles = []string{}
+		} else {
+			a.model.SelectedFiles = changedPaths(files, true)
+		}
+
+	case "n":
+		// Include or leave out every new file at once
+		a.model.IncludeUntracked = !a.model.IncludeUntracked
+		for _, path := range a.model.Status.Untracked {
+			if a.model.IsFileSelected(path) != a.model.IncludeUntracked {
+				a.model.ToggleFile(path)
+			}
+		}
+		a.model.Notice = models.T(models.TextUntrackedOff)
+		if a.model.IncludeUntracked {
+			a.model.Notice = models.T(models.TextUntrackedOn)
+		}
+
+	case "enter":
+		if len(a.model.SelectedFiles) == 0 {
+			return a, nil
+		}
+		a.model.StagingMode = false
+		return a, a.enterDescriptionMode()
+	}
+
+	return a, nil
+}
+
+// changedPaths extracts the paths of the given status files
+func changedPaths(files []models.StatusFile, includeUntracked bool) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.Category == models.FileUntracked && !includeUntracked {
+			continue
+		}
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+// trimLastRune drops the last character of a typed input. Slicing bytes
+// would cut Cyrillic letters, which take two, in half.
+func trimLastRune(input string) string {
+	runes := []rune(input)
+	if len(runes) == 0 {
+		return input
+	}
+	return string(runes[:len(runes)-1])
+}
+
+// startCheckpoint begins the checkpoint flow
+func (a *App) startCheckpoint() tea.Cmd {
+	// Let the user pick the files first when there is something to pick
+	if a.model.Status != nil {
+		if files := a.model.Status.ChangedFiles(); len(files) > 0 {
+			a.model.StagingMode = true
+			a.model.StagingCursor = 0
+			a.model.SelectedFiles = changedPaths(files, a.model.IncludeUntracked)
+			return nil
+		}
+	}
+	return a.enterDescriptionMode()
+}
+
+// enterDescriptionMode switches to the checkpoint description step
+func (a *App) enterDescriptionMode() tea.Cmd {
+	// Enter description mode via async message (like history)
+	a.model.Loading = true
+	a.model.LoadingText = models.T("Ловлю вдохновение...")
+	paths := a.model.SelectedFiles
+	return func() tea.Msg {
+		return models.DescriptionModeMsg{
+			Suggestions:   a.gitService.Suggestions(),
+			EstimatedSize: a.gitService.EstimateSize(paths),
+		}
+	}
+}
+
+// handleFilesInput handles input when browsing the changed files list
+func (a *App) handleFilesInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	files := a.model.Status.Files()
+
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyBackspace:
+		a.model.FilesMode = false
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		a.model.FilesMode = false
+		return a, nil
+
+	case "up", "k":
+		if a.model.FilesSelected > 0 {
+			a.model.FilesSelected--
+		}
+
+	case "down", "j":
+		if a.model.FilesSelected < len(files)-1 {
+			a.model.FilesSelected++
+		}
+
+	case "u":
+		if a.model.FilesSelected < len(files) {
+			file := files[a.model.FilesSelected]
+			if file.Category != models.FileStaged {
+				return a, nil
+			}
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Убираю из сейва...")
+			return a, func() tea.Msg {
+				return a.gitService.Unstage(file.Path)
+			}
+		}
+
+	case "U":
+		if len(a.model.Status.Staged) > 0 {
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Убираю из сейва...")
+			return a, a.gitService.UnstageAll
+		}
+
+	case "r":
+		if a.model.FilesSelected < len(files) {
+			file := files[a.model.FilesSelected]
+			if file.Category != models.FileDeleted {
+				return a, nil
+			}
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Возвращаю файл...")
+			return a, func() tea.Msg {
+				return a.gitService.RestoreDeletedFile(file.Path)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// handleDescriptionInput handles input when in description mode
+func (a *App) handleDescriptionInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle number keys for quick selection first
+	if len(msg.Runes) == 1 {
+		r := msg.Runes[0]
+		if r >= '1' && r <= '9' {
+			index := int(r - '1')
+			if index < min(len(a.model.Suggestions), models.QuickPickCount) {
+				a.model.DescriptionInput = a.model.Suggestions[index]
+				// Don't save emoji the user never saw
+				if a.model.PlainText {
+					a.model.DescriptionInput = strings.TrimSpace(ui.Plain(a.model.DescriptionInput))
+				}
+				return a, nil
+			}
+		}
+	}
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		// Exit description mode
+		a.model.DescriptionMode = false
+		a.model.DescriptionInput = ""
+		a.model.SelectedFiles = nil
+		a.model.SyncAfterCheckpoint = false
+		a.model.MarkerMode = false
+		a.model.SquashMode = false
+		a.model.StagedOnly = false
+		return a, nil
+
+	case tea.KeyEnter:
+		// Create checkpoint with description
+		description := a.model.DescriptionInput
+		// Fill in the default inside the commands, it looks at the repository
+		defaulted := func() string {
+			if description == "" {
+				return a.gitService.DefaultMessage()
+			}
+			return description
+		}
+		if a.model.SquashMode {
+			a.model.SquashMode = false
+			a.model.DescriptionMode = false
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Схлопываю сейвы...")
+			return a, func() tea.Msg {
+				return a.gitService.SquashToolCheckpoints(defaulted())
+			}
+		}
+		opts := timekeeper.CheckpointOptions{
+			Paths:       a.model.SelectedFiles,
+			AllowEmpty:  a.model.MarkerMode,
+			AuthorName:  os.Getenv(envAuthorName),
+			AuthorEmail: os.Getenv(envAuthorEmail),
+			StagedOnly:  a.model.StagedOnly,
+		}
+		a.model.SelectedFiles = nil
+		a.model.MarkerMode = false
+		a.model.StagedOnly = false
+		a.model.DescriptionMode = false
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Сейвлю вайб...")
+		a.confirmCheckpoint = func() tea.Msg {
+			confirmed := opts
+			confirmed.Confirmed = true
+			return a.gitService.CreateCheckpoint(defaulted(), confirmed)
+		}
+		return a, func() tea.Msg {
+			return a.gitService.CreateCheckpoint(defaulted(), opts)
+		}
+
+	case tea.KeyBackspace:
+		a.model.DescriptionInput = trimLastRune(a.model.DescriptionInput)
+		return a, nil
+
+	case tea.KeyRunes:
+		// Add typed characters (but not numbers since we handled them above)
+		r := msg.Runes[0]
+		if r < '1' || r > '9' {
+			a.model.DescriptionInput += string(msg.Runes)
+		}
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		a.model.Quitting = true
+		return a, tea.Quit
+	}
+
+	return a, nil
+}
+
+// handleHistoryInput handles input when in history mode
+func (a *App) handleHistoryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Whatever moved the selection, the list follows it
+	defer a.model.KeepHistorySelectionVisible()
+
+	// Typing the filter narrows the list as it goes, arrows still move
+	if a.model.HistoryFiltering {
+		switch msg.Type {
+		case tea.KeyEscape:
+			a.model.HistoryFiltering = false
+			a.model.SetHistoryFilter("")
+			return a, nil
+
+		case tea.KeyEnter:
+			a.model.HistoryFiltering = false
+			return a, nil
+
+		case tea.KeyCtrlC:
+			a.model.Quitting = true
+			return a, tea.Quit
+
+		case tea.KeyBackspace:
+			if a.model.HistoryFilter != "" {
+				a.model.SetHistoryFilter(trimLastRune(a.model.HistoryFilter))
+			}
+			return a, a.loadHistoryStats()
+
+		case tea.KeyRunes, tea.KeySpace:
+			a.model.SetHistoryFilter(a.model.HistoryFilter + string(msg.Runes))
+			return a, a.loadHistoryStats()
+		}
+	}
+
+	// Handle Escape key using Type for better reliability
+	switch msg.Type {
+	case tea.KeyEscape:
+		// Escape drops the filter first, then the compare mark, then goes
+		// back to main menu
+		if a.model.HistoryFilter != "" {
+			a.model.SetHistoryFilter("")
+			return a, nil
+		}
+		if a.model.HistoryCompareBase != "" {
+			a.model.HistoryCompareBase = ""
+			return a, nil
+		}
+		a.model.HistoryMode = false
+		return a, nil
+
+	case tea.KeyBackspace:
+		// Backspace also goes back to main menu (more intuitive)
+		a.model.HistoryMode = false
+		return a, nil
+	}
+
+	// A filter matching nothing leaves no checkpoint to act on
+	if a.model.HistoryFilter != "" && !a.model.HistorySelectionListed() {
+		switch msg.String() {
+		case "ctrl+c", "q":
+			a.model.Quitting = true
+			return a, tea.Quit
+		case "/":
+			a.model.HistoryFiltering = true
+		}
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "q":
+		// 'q' now quits from history mode too for consistency
+		a.model.Quitting = true
+		return a, tea.Quit
+
+	case "esc", "escape":
+		// Fallback for terminals where Type detection doesn't work
+		if a.model.HistoryFilter != "" {
+			a.model.SetHistoryFilter("")
+			return a, nil
+		}
+		if a.model.HistoryCompareBase != "" {
+			a.model.HistoryCompareBase = ""
+			return a, nil
+		}
+		a.model.HistoryMode = false
+		return a, nil
+
+	case "/":
+		// Narrow the list by description, author, tag or hash
+		a.model.HistoryFiltering = true
+		return a, nil
+
+	case "up", "k":
+		// Move by rows, a collapsed group is a single one
+		rows := a.model.HistoryRows()
+		if row := models.HistoryRowOf(rows, a.model.HistorySelected); row > 0 {
+			a.model.HistorySelected = rows[row-1].Index
+		} else if a.model.WrapNavigation && len(rows) > 0 {
+			a.model.HistorySelected = rows[len(rows)-1].Index
+		}
+		return a, a.loadHistoryStats()
+
+	case "down", "j":
+		rows := a.model.HistoryRows()
+		if row := models.HistoryRowOf(rows, a.model.HistorySelected); row < len(rows)-1 {
+			a.model.HistorySelected = rows[row+1].Index
+		} else if a.model.WrapNavigation && len(rows) > 0 {
+			a.model.HistorySelected = 0
+		}
+		return a, a.loadHistoryStats()
+
+	case "pgup", "pgdown":
+		// Jump a screenful of rows
+		rows := a.model.HistoryRows()
+		if len(rows) == 0 {
+			return a, nil
+		}
+		row := models.HistoryRowOf(rows, a.model.HistorySelected)
+		if msg.String() == "pgup" {
+			row = max(row-a.model.HistoryPageSize(), 0)
+		} else {
+			row = min(row+a.model.HistoryPageSize(), len(rows)-1)
+		}
+		a.model.HistorySelected = rows[row].Index
+		return a, a.loadHistoryStats()
+
+	case "c":
+		// Collapse runs of tool checkpoints, or show everything again
+		a.model.HistoryCompact = !a.model.HistoryCompact
+		rows := a.model.HistoryRows()
+		if len(rows) > 0 {
+			a.model.HistorySelected = rows[models.HistoryRowOf(rows, a.model.HistorySelected)].Index
+		}
+
+	case "e", "right", "left":
+		// Expand the selected group, or fold it back
+		if a.model.HistoryCompact {
+			a.model.ToggleHistoryGroup()
+		}
+
+	case "u":
+		// Fetch the history a shallow clone left out
+		if a.model.HistoryShallow {
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Докачиваю историю...")
+			return a, func() tea.Msg {
+				if msg, ok := a.gitService.Unshallow().(models.ErrMsg); ok {
+					return msg
+				}
+				return a.gitService.LoadCheckpoints(timekeeper.HistoryPageLimit)
+			}
+		}
+
+	case "i":
+		// Show the full message of the selected checkpoint
+		a.model.HistoryDetail = !a.model.HistoryDetail
+
+	case "s":
+		// Show what the selected checkpoint changed, like git show
+		if a.model.HistorySelected < len(a.model.Checkpoints) {
+			hash := a.model.Checkpoints[a.model.HistorySelected].Hash
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Собираю изменения...")
+			return a, func() tea.Msg {
+				return a.gitService.CheckpointDiff(hash)
+			}
+		}
+
+	case "w":
+		// Compare the working tree with the selected checkpoint
+		if a.model.HistorySelected < len(a.model.Checkpoints) {
+			checkpoint := a.model.Checkpoints[a.model.HistorySelected]
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Собираю изменения...")
+			return a, func() tea.Msg {
+				return a.gitService.DiffWorkingTreeAgainst(checkpoint.Hash)
+			}
+		}
+
+	case "b":
+		// Blame a file as it was at the selected checkpoint
+		if a.model.HistorySelected < len(a.model.Checkpoints) {
+			a.model.BlameMode = true
+			a.model.BlameHash = a.model.Checkpoints[a.model.HistorySelected].Hash
+			a.model.BlameInput = ""
+		}
+
+	case "B":
+		// Branch off the selected checkpoint
+		if a.model.HistorySelected < len(a.model.Checkpoints) {
+			a.model.BranchFromMode = true
+			a.model.BranchFromHash = a.model.Checkpoints[a.model.HistorySelected].Hash
+			a.model.BranchFromInput = ""
+		}
+
+	case "n":
+		// Write or change the note of the selected checkpoint
+		if a.model.HistoryBranch != "" {
+			a.model.Warning = models.T(models.TextHistoryReadOnly)
+		} else if a.model.HistorySelected < len(a.model.Checkpoints) {
+			checkpoint := a.model.Checkpoints[a.model.HistorySelected]
+			a.model.NoteMode = true
+			a.model.NoteHash = checkpoint.Hash
+			a.model.NoteInput = checkpoint.Note
+		}
+
+	case "t":
+		// Tag the selected checkpoint to find it later
+		if a.model.HistorySelected < len(a.model.Checkpoints) {
+			a.model.TagMode = true
+			a.model.TagHash = a.model.Checkpoints[a.model.HistorySelected].Hash
+			a.model.TagInput = ""
+		}
+
+	case "p":
+		// Pin or unpin the selected checkpoint
+		if a.model.HistoryBranch != "" {
+			a.model.Warning = models.T(models.TextHistoryReadOnly)
+		} else if a.model.HistorySelected < len(a.model.Checkpoints) {
+			checkpoint := a.model.Checkpoints[a.model.HistorySelected]
+			return a, func() tea.Msg {
+				if checkpoint.Pinned {
+					return a.gitService.UnpinCheckpoint(checkpoint.Hash)
+				}
+				return a.gitService.PinCheckpoint(checkpoint.Hash)
+			}
+		}
+
+	case "v":
+		// Check the integrity chain of the history
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Проверяю цепочку...")
+		return a, a.gitService.VerifyChain
+
+	case " ":
+		// Mark the selected checkpoint to compare another one with, or
+		// clear the mark
+		if a.model.HistorySelected < len(a.model.Checkpoints) {
+			hash := a.model.Checkpoints[a.model.HistorySelected].Hash
+			if a.model.HistoryCompareBase == hash {
+				hash = ""
+			}
+			a.model.HistoryCompareBase = hash
+		}
+
+	case "d":
+		// Compare the selected checkpoint with the marked one, without a
+		// mark switch between absolute and relative dates
+		if a.model.HistoryCompareBase == "" {
+			a.model.HistoryRelativeDates = !a.model.HistoryRelativeDates
+		} else if a.model.HistorySelected < len(a.model.Checkpoints) {
+			base, hash := a.model.HistoryCompareBase, a.model.Checkpoints[a.model.HistorySelected].Hash
+			if base == hash {
+				a.model.Warning = models.T(models.TextCompareSame)
+				return a, nil
+			}
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Собираю изменения...")
+			return a, func() tea.Msg {
+				return a.gitService.CompareCheckpoints(base, hash)
+			}
+		}
+
+	case "enter":
+		rows := a.model.HistoryRows()
+		if len(rows) > 0 && rows[models.HistoryRowOf(rows, a.model.HistorySelected)].Count > 1 {
+			// A collapsed group opens instead of rolling back to its newest
+			a.model.ToggleHistoryGroup()
+		} else if a.model.HistoryBranch != "" {
+			a.model.Warning = models.T(models.TextHistoryReadOnly)
+		} else if a.model.HistorySelected < len(a.model.Checkpoints) {
+			checkpoint := a.model.Checkpoints[a.model.HistorySelected]
+			a.model.Loading = true
+			a.model.LoadingText = models.T("Прикидываю последствия...")
+			return a, func() tea.Msg {
+				return a.gitService.RollbackPreview(checkpoint.Hash)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// runHooks runs the hooks configured for event in the background
+func (a *App) runHooks(event string) tea.Cmd {
+	return func() tea.Msg {
+		return a.gitService.RunHooks(event)
+	}
+}
+
+// hookReport describes the failed hooks, and what the others printed when
+// their output is asked for
+func hookReport(msg models.HooksMsg) (failures, outputs []string) {
+	for _, result := range msg.Results {
+		switch {
+		case result.Err != "":
+			detail := result.Err
+			if result.Output != "" {
+				detail += ": " + result.Output
+			}
+			failures = append(failures, fmt.Sprintf(models.T(models.TextHookFailed), result.Command, detail))
+		case msg.ShowOutput && result.Output != "":
+			outputs = append(outputs, fmt.Sprintf(models.T(models.TextHookOutput), result.Command, result.Output))
+		}
+	}
+	return failures, outputs
+}
+
+// historyStatsWindow is how many checkpoints on each side of the selection
+// get their diff stats when the terminal height is unknown
+const historyStatsWindow = 30
+
+// loadHistoryStats asks for the diff stats of the checkpoints around the
+// history selection that don't have them yet, one batch at a time
+func (a *App) loadHistoryStats() tea.Cmd {
+	if !a.model.HistoryMode || a.model.HistoryStatsLoading {
+		return nil
+	}
+
+	window := a.model.Height
+	if window <= 0 {
+		window = historyStatsWindow
+	}
+	from := max(a.model.HistorySelected-window, 0)
+	to := min(a.model.HistorySelected+window+1, len(a.model.Checkpoints))
+
+	var hashes []string
+	for _, checkpoint := range a.model.Checkpoints[from:to] {
+		if _, ok := a.model.HistoryStats[checkpoint.Hash]; !ok {
+			hashes = append(hashes, checkpoint.Hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	a.model.HistoryStatsLoading = true
+	return func() tea.Msg {
+		return a.gitService.CheckpointStats(hashes)
+	}
+}
+
+// loadMoreHistory fetches the next page of the history once the selection
+// nears the end of the loaded checkpoints
+func (a *App) loadMoreHistory() tea.Cmd {
+	if !a.model.HistoryMode || len(a.model.Checkpoints) == 0 || !a.model.NeedsMoreHistory() {
+		return nil
+	}
+
+	a.model.HistoryLoadingMore = true
+	after := a.model.Checkpoints[len(a.model.Checkpoints)-1].Hash
+	return func() tea.Msg {
+		return a.gitService.LoadMoreCheckpoints(after, timekeeper.HistoryPageLimit)
+	}
+}
+
+// selectMenuItem moves the selection to the given item and activates it
+func (a *App) selectMenuItem(item string) tea.Cmd {
+	for i, menuItem := range a.model.GetMenuItems() {
+		if menuItem == item {
+			a.model.Selected = i
+			return a.handleMenuSelection()
+		}
+	}
+	return nil
+}
+
+// handleMenuSelection processes the selected menu item
+func (a *App) handleMenuSelection() tea.Cmd {
+	menuItems := a.model.GetMenuItems()
 	if a.model.Selected >= len(menuItems) {
 		return nil
 	}
@@ -342,33 +2241,77 @@ func (a *App) handleMenuSelection() tea.Cmd {
 	switch selectedItem {
 	case models.MenuInitGit:
 		a.model.Loading = true
-		a.model.LoadingText = "Настраиваю пространство..."
+		a.model.LoadingText = models.T("Настраиваю пространство...")
 		return a.gitService.InitGit
 
 	case models.MenuCreateCheckpoint:
-		// Enter description mode via async message (like history)
+		return a.startCheckpoint()
+
+	case models.MenuCreateMarker:
+		// Markers skip file selection and leave the index alone, like
+		// git commit --allow-empty: only what was already staged goes in
+		a.model.MarkerMode = true
+		a.model.SelectedFiles = nil
+		a.model.StagedOnly = true
+		return a.enterDescriptionMode()
+
+	case models.MenuSquash:
 		a.model.Loading = true
-		a.model.LoadingText = "Ловлю вдохновение..."
-		return func() tea.Msg {
-			return models.DescriptionModeMsg{
-				Suggestions: models.DefaultSuggestions,
-			}
-		}
+		a.model.LoadingText = models.T("Ищу сейвы для схлопывания...")
+		return a.gitService.LoadSquashCandidates
+
+	case models.MenuSaveAndSync:
+		a.model.SyncAfterCheckpoint = true
+		return a.startCheckpoint()
 
 	case models.MenuViewHistory:
 		a.model.Loading = true
-		a.model.LoadingText = "Вспоминаем былое..."
-		return a.gitService.LoadCheckpoints
+		a.model.LoadingText = models.T("Вспоминаем былое...")
+		return func() tea.Msg {
+			return a.gitService.LoadCheckpoints(timekeeper.HistoryPageLimit)
+		}
 
 	case models.MenuRollback:
 		a.model.Loading = true
-		a.model.LoadingText = "Вспоминаем былое..."
-		return a.gitService.LoadCheckpoints
+		a.model.LoadingText = models.T("Вспоминаем былое...")
+		return func() tea.Msg {
+			return a.gitService.LoadCheckpoints(timekeeper.HistoryPageLimit)
+		}
 
 	case models.MenuSync:
 		a.model.Loading = true
-		a.model.LoadingText = "Синхронизирую потоки..."
+		a.model.LoadingText = models.T("Синхронизирую потоки...")
 		return a.gitService.SyncWithRemote
+
+	case models.MenuViewChanges:
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Собираю изменения...")
+		return a.gitService.WorkingTreeDiff
+
+	case models.MenuUpdateSubmodules:
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Подтягиваю субмодули...")
+		return a.gitService.UpdateSubmodules
+
+	case models.MenuReturnToPresent:
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Возвращаюсь в настоящее...")
+		return a.gitService.ReturnToPresent
+
+	case models.MenuMergeContinue:
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Завершаю слияние...")
+		return a.gitService.MergeContinue
+
+	case models.MenuMergeAbort:
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Отменяю слияние...")
+		return a.gitService.MergeAbort
+
+	case models.MenuBranches:
+		a.model.Loading = true
+		a.model.LoadingText = models.T("Собираю ветки...")
+		return a.gitService.ListBranches
 	}
 
 	return nil
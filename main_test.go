@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+
+	"time-machine/internal/models"
+)
+
+func TestTrimLastRune(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"", ""},
+		{"a", ""},
+		{"abc", "ab"},
+		{"Привет", "Приве"},
+		{"сейв 🌊", "сейв "},
+	}
+	for _, tt := range tests {
+		if got := trimLastRune(tt.input); got != tt.want {
+			t.Errorf("trimLastRune(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDescriptionBackspaceCyrillic(t *testing.T) {
+	a := &App{model: models.Model{DescriptionMode: true}}
+	for _, r := range "Привет" {
+		a.handleDescriptionInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	for i := 0; i < 3; i++ {
+		a.handleDescriptionInput(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	if got := a.model.DescriptionInput; got != "При" {
+		t.Errorf("DescriptionInput = %q, want %q", got, "При")
+	}
+}
+
+func TestHistoryStatsErrorAllowsRetry(t *testing.T) {
+	a := &App{model: models.Model{HistoryMode: true, HistoryStatsLoading: true}}
+	a.Update(models.ErrMsg{Error: errors.New("repository unavailable")})
+	if a.model.HistoryStatsLoading {
+		t.Error("HistoryStatsLoading stayed set after the stats failed")
+	}
+}